@@ -0,0 +1,166 @@
+package golog
+
+import (
+	"net"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// combinedTimeLayout is the timestamp layout of the NCSA log formats
+const combinedTimeLayout = "02/Jan/2006:15:04:05 -0700"
+
+// accessLineKey is the key of the field that marks access lines
+const accessLineKey = "golog.access"
+
+// accessLineField marks an entry as an access line for a request received
+// at start. It has zapcore.SkipType, so every other encoder leaves it out.
+func accessLineField(start time.Time) zapcore.Field {
+	return zapcore.Field{Key: accessLineKey, Type: zapcore.SkipType, Interface: start}
+}
+
+// combinedPool provides the buffers returned by combinedEncoder
+var combinedPool = buffer.NewPool()
+
+// combinedEncoder writes access lines in the Apache/NCSA combined log format
+//
+//	host - user [time] "method path?query proto" status bytes "referer" "user agent"
+//
+// from the fields logged by HTTPMiddleware: "remote_addr", "user", "method",
+// "path", "query", "proto", "status", "bytes", "referer" and "user_agent".
+// Only entries marked with accessLineField are access lines; the rest are
+// left out, so an output using the "combined" encoding only receives access
+// lines while other outputs receive everything.
+type combinedEncoder struct {
+	// fields holds the fields added with With
+	*zapcore.MapObjectEncoder
+}
+
+// newCombinedEncoder returns a combinedEncoder without fields
+func newCombinedEncoder() *combinedEncoder {
+	return &combinedEncoder{MapObjectEncoder: zapcore.NewMapObjectEncoder()}
+}
+
+// Clone copies the encoder, so fields added to the clone don't affect e
+func (e *combinedEncoder) Clone() zapcore.Encoder {
+	clone := newCombinedEncoder()
+	for key, value := range e.Fields {
+		clone.Fields[key] = value
+	}
+	return clone
+}
+
+// EncodeEntry writes ent as one combined log format line
+func (e *combinedEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	var start time.Time
+	access := false
+	for _, f := range fields {
+		if f.Type == zapcore.SkipType && f.Key == accessLineKey {
+			start, _ = f.Interface.(time.Time)
+			access = true
+			break
+		}
+	}
+	buf := combinedPool.Get()
+	if !access {
+		return buf, nil
+	}
+
+	// The marker has zapcore.SkipType, so AddTo leaves it out
+	all := e.Clone().(*combinedEncoder)
+	for _, f := range fields {
+		f.AddTo(all)
+	}
+	// %t is the time the request was received, not when it was logged
+	if start.IsZero() {
+		start = ent.Time
+	}
+
+	host := all.str("remote_addr")
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	request := all.str("method") + " " + all.str("path")
+	if query := all.str("query"); query != "" {
+		request += "?" + query
+	}
+	if proto := all.str("proto"); proto != "" {
+		request += " " + proto
+	}
+
+	buf.AppendString(orDash(host))
+	buf.AppendString(" - ")
+	buf.AppendString(orDash(all.str("user")))
+	buf.AppendString(" [")
+	buf.AppendString(start.Format(combinedTimeLayout))
+	buf.AppendString("] ")
+	appendQuoted(buf, request)
+	buf.AppendByte(' ')
+	buf.AppendString(orDash(all.str("status")))
+	buf.AppendByte(' ')
+	if bytes := formatValue(all.Fields["bytes"]); bytes != "" && bytes != "0" {
+		buf.AppendString(bytes)
+	} else {
+		buf.AppendByte('-')
+	}
+	buf.AppendByte(' ')
+	appendQuoted(buf, orDash(all.str("referer")))
+	buf.AppendByte(' ')
+	appendQuoted(buf, orDash(all.str("user_agent")))
+	buf.AppendByte('\n')
+	return buf, nil
+}
+
+// str returns the field key formatted as a string, or "" if it is missing
+func (e *combinedEncoder) str(key string) string {
+	return formatValue(e.Fields[key])
+}
+
+// formatValue formats a value added to a zapcore.MapObjectEncoder
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case interface{ String() string }:
+		return v.String()
+	default:
+		return ""
+	}
+}
+
+// orDash returns s, or "-" if s is empty, as the log format requires
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// appendQuoted appends s in double quotes, escaping quotes, backslashes and
+// non-printable bytes the way Apache does
+func appendQuoted(buf *buffer.Buffer, s string) {
+	const hex = "0123456789abcdef"
+	buf.AppendByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"' || c == '\\':
+			buf.AppendByte('\\')
+			buf.AppendByte(c)
+		case c < 0x20 || c >= 0x7f:
+			buf.AppendString(`\x`)
+			buf.AppendByte(hex[c>>4])
+			buf.AppendByte(hex[c&0xf])
+		default:
+			buf.AppendByte(c)
+		}
+	}
+	buf.AppendByte('"')
+}
@@ -0,0 +1,140 @@
+package golog
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestCombinedEncoder(t *testing.T) {
+	enc := newCombinedEncoder().Clone()
+	zap.String("request_id", "abc").AddTo(enc)
+	zap.String("method", "GET").AddTo(enc)
+	zap.String("path", "/apache_pb.gif").AddTo(enc)
+	start := time.Date(2000, 10, 10, 13, 55, 36, 0, time.FixedZone("", -7*3600))
+	ent := zapcore.Entry{Time: start.Add(2 * time.Second), Message: "http request"}
+
+	tests := []struct {
+		name   string
+		fields []zapcore.Field
+		want   string
+	}{
+		{
+			name: "full",
+			fields: []zapcore.Field{
+				zap.Int("status", 200),
+				zap.Int64("bytes", 2326),
+				zap.String("remote_addr", "127.0.0.1:51234"),
+				zap.String("proto", "HTTP/1.0"),
+				zap.String("query", "q=1"),
+				zap.String("user", "frank"),
+				zap.String("referer", "http://www.example.com/start.html"),
+				zap.String("user_agent", `Mozilla/4.08 [en] (Win98; "I" ;Nav)`),
+				accessLineField(start),
+			},
+			want: `127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif?q=1 HTTP/1.0" 200 2326 "http://www.example.com/start.html" "Mozilla/4.08 [en] (Win98; \"I\" ;Nav)"` + "\n",
+		},
+		{
+			name: "minimal",
+			fields: []zapcore.Field{
+				zap.Int("status", 304),
+				zap.Int64("bytes", 0),
+				zap.String("remote_addr", "[::1]:8080"),
+				zap.String("user_agent", "bad\x01agent"),
+				accessLineField(start),
+			},
+			want: `::1 - - [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif" 304 - "-" "bad\x01agent"` + "\n",
+		},
+		{
+			name:   "not an access line",
+			fields: []zapcore.Field{zap.String("status", "ok"), zap.String("user_agent", "curl")},
+			want:   "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf, err := enc.EncodeEntry(ent, tt.fields)
+			if err != nil {
+				t.Fatalf("EncodeEntry failed: %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	// fields from one entry don't leak into the next
+	if _, ok := enc.(*combinedEncoder).Fields["status"]; ok {
+		t.Error("Expected EncodeEntry to leave the encoder's fields unchanged")
+	}
+}
+
+func TestCombinedOutput(t *testing.T) {
+	dir := t.TempDir()
+	jsonFile, accessFile := filepath.Join(dir, "app.log"), filepath.Join(dir, "access.log")
+	config := DefaultConfig()
+	config.Outputs = []OutputConfig{
+		{Paths: []string{jsonFile}, Level: DebugLevel},
+		{Paths: []string{accessFile}, Level: DebugLevel, Encoding: "combined"},
+	}
+	logger := newFileLogger(t, config)
+
+	handler := logger.HTTPMiddleware(HTTPConfig{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		FromContext(r.Context()).Info("handling")
+		w.Write([]byte("hello"))
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/hello?name=gopher", nil)
+	req.Header.Set("Referer", "https://example.com/")
+	req.Header.Set("User-Agent", "GoAccess-test")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	logger.Info("job finished", Field("status", "ok"))
+	logger.Sync()
+
+	app := readFile(t, jsonFile)
+	if n := strings.Count(app, `"msg"`); n != 3 {
+		t.Errorf("Expected every entry in the JSON output, got %d", n)
+	}
+	if strings.Contains(app, accessLineKey) {
+		t.Errorf("Expected the access line marker to be left out of the JSON output, got %q", app)
+	}
+	access := readFile(t, accessFile)
+	pattern := `^192\.0\.2\.1 - - \[\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}\] "GET /hello\?name=gopher HTTP/1\.1" 200 5 "https://example\.com/" "GoAccess-test"\n$`
+	if !regexp.MustCompile(pattern).MatchString(access) {
+		t.Errorf("Expected one combined log line, got %q", access)
+	}
+}
+
+func TestCombinedValidate(t *testing.T) {
+	dir := t.TempDir()
+	access := OutputConfig{Paths: []string{filepath.Join(dir, "access.log")}, Encoding: "combined"}
+	app := OutputConfig{Paths: []string{filepath.Join(dir, "app.log")}}
+
+	config := DefaultConfig()
+	config.Encoding = "combined"
+	config.Outputs = []OutputConfig{access, app}
+	if err := config.Validate(); err == nil {
+		t.Error("Expected an error for combined as the encoding of every output")
+	}
+	config.Outputs = []OutputConfig{access, {Paths: app.Paths, Encoding: "json"}}
+	if err := config.Validate(); err != nil {
+		t.Errorf("Expected combined next to a JSON output to be valid, got %v", err)
+	}
+
+	config = DefaultConfig()
+	config.Encoding = "combined"
+	if err := config.Validate(); err == nil {
+		t.Error("Expected an error for combined as the only encoding")
+	}
+	config.Encoding = "json"
+	config.Outputs = []OutputConfig{access}
+	if err := config.Validate(); err == nil {
+		t.Error("Expected an error for a combined output on its own")
+	}
+}
@@ -0,0 +1,361 @@
+package golog
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigError reports a problem with a single configuration key
+type ConfigError struct {
+	// Key is the offending key: a dotted path such as "rotate.maxSize" for
+	// config files, or the variable name for environment variables
+	Key string
+	// Err describes the problem
+	Err error
+}
+
+// Error implements the error interface
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("golog: config %s: %s", e.Key, strings.TrimPrefix(e.Err.Error(), "golog: "))
+}
+
+// Unwrap returns the underlying error
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+// DefaultConfig returns the configuration LoadConfig and ConfigFromEnv start
+// from: Info level JSON output to stdout, with internal errors to stderr
+func DefaultConfig() Config {
+	return Config{
+		Level:            InfoLevel,
+		Encoding:         "json",
+		OutputPaths:      []string{"stdout"},
+		ErrorOutputPaths: []string{"stderr"},
+	}
+}
+
+// LoadConfig reads a Config from a JSON (.json) or YAML (.yaml, .yml) file.
+// Keys use the names in the Config struct tags, e.g.
+//
+//	level: debug
+//	encoding: console
+//	outputPaths: [stdout, /var/log/app.log]
+//	rotate:
+//	  maxSize: 100
+//
+// Keys missing from the file keep their DefaultConfig values, and an empty
+// file loads as DefaultConfig. Unknown keys and invalid values are reported
+// together as ConfigErrors.
+func LoadConfig(path string) (Config, error) {
+	config, err := loadConfig(path)
+	if errors.Is(err, errEmptyConfig) {
+		return config, nil
+	}
+	return config, err
+}
+
+// errEmptyConfig is returned by loadConfig for files without a document
+var errEmptyConfig = errors.New("golog: config file is empty")
+
+// loadConfig is LoadConfig, except that it returns DefaultConfig and
+// errEmptyConfig for an empty or whitespace-only file
+func loadConfig(path string) (Config, error) {
+	config := DefaultConfig()
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json", ".yaml", ".yml":
+	default:
+		return config, fmt.Errorf("golog: unsupported config file extension %q", ext)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return config, fmt.Errorf("golog: can't read config: %w", err)
+	}
+
+	// JSON is a subset of YAML, so both formats go through the YAML decoder
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return config, fmt.Errorf("golog: can't parse config %s: %w", path, err)
+	}
+	if len(root.Content) == 0 {
+		return config, fmt.Errorf("%w: %s", errEmptyConfig, path)
+	}
+
+	var errs ConfigErrors
+	decodeNode(root.Content[0], reflect.ValueOf(&config).Elem(), "", &errs)
+	return config, errs.err()
+}
+
+// ConfigFromEnv builds a Config from environment variables named after the
+// Config fields in upper snake case, with prefix and an underscore in front.
+// For example, with prefix "GOLOG":
+//
+//	GOLOG_LEVEL=debug
+//	GOLOG_ENCODING=console
+//	GOLOG_OUTPUT_PATHS=stdout,/var/log/app.log
+//	GOLOG_NAMED_LEVELS=billing.*=debug,db=warn
+//	GOLOG_ROTATE_MAX_SIZE=100
+//
+// Lists are comma-separated. Unset variables keep their DefaultConfig values.
+// Invalid values are reported together as ConfigErrors.
+func ConfigFromEnv(prefix string) (Config, error) {
+	config := DefaultConfig()
+	err := config.ApplyEnv(prefix)
+	return config, err
+}
+
+// ApplyEnv overrides c with the environment variables described in
+// ConfigFromEnv. Use it to layer environment overrides on top of a file
+// loaded with LoadConfig.
+func (c *Config) ApplyEnv(prefix string) error {
+	if prefix != "" && !strings.HasSuffix(prefix, "_") {
+		prefix += "_"
+	}
+	var errs ConfigErrors
+	applyEnv(reflect.ValueOf(c).Elem(), prefix, &errs)
+	return errs.err()
+}
+
+// decodeNode decodes a YAML mapping into the struct v, adding problems to
+// errs with keys relative to path
+func decodeNode(node *yaml.Node, v reflect.Value, path string, errs *ConfigErrors) {
+	if node.Kind != yaml.MappingNode {
+		errs.add(keyOrRoot(path), fmt.Errorf("expected a mapping, got %s", node.Tag))
+		return
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		name, value := node.Content[i].Value, node.Content[i+1]
+		key := joinKey(path, name)
+
+		field, ok := fieldByTag(v, name)
+		if !ok {
+			errs.add(key, errors.New("unknown key"))
+			continue
+		}
+
+		if isNestedStruct(field.Type()) {
+			if field.Kind() == reflect.Pointer {
+				if field.IsNil() {
+					field.Set(reflect.New(field.Type().Elem()))
+				}
+				field = field.Elem()
+			}
+			decodeNode(value, field, key, errs)
+			continue
+		}
+
+		if field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.Struct &&
+			isNestedStruct(field.Type().Elem()) && value.Kind == yaml.SequenceNode {
+			items := reflect.MakeSlice(field.Type(), len(value.Content), len(value.Content))
+			for i, item := range value.Content {
+				decodeNode(item, items.Index(i), fmt.Sprintf("%s[%d]", key, i), errs)
+			}
+			field.Set(items)
+			continue
+		}
+
+		if err := value.Decode(field.Addr().Interface()); err != nil {
+			errs.add(key, err)
+		}
+	}
+}
+
+// applyEnv sets the fields of struct v from environment variables starting
+// with prefix, adding problems to errs, and reports whether any were set
+func applyEnv(v reflect.Value, prefix string, errs *ConfigErrors) bool {
+	set := false
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := tagName(t.Field(i))
+		if name == "" {
+			continue
+		}
+		field := v.Field(i)
+		envName := prefix + upperSnake(name)
+
+		if isNestedStruct(field.Type()) {
+			nested := field
+			if field.Kind() == reflect.Pointer {
+				nested = reflect.New(field.Type().Elem()).Elem()
+				if !field.IsNil() {
+					nested.Set(field.Elem())
+				}
+			}
+			ok := applyEnv(nested, envName+"_", errs)
+			if ok && field.Kind() == reflect.Pointer {
+				field.Set(nested.Addr())
+			}
+			set = set || ok
+			continue
+		}
+
+		text, ok := os.LookupEnv(envName)
+		if !ok {
+			continue
+		}
+		if err := setFromString(field, text); err != nil {
+			errs.add(envName, err)
+			continue
+		}
+		set = true
+	}
+	return set
+}
+
+// setFromString parses text into v according to its type
+func setFromString(v reflect.Value, text string) error {
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(text))
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		elem := reflect.New(v.Type().Elem())
+		if err := setFromString(elem.Elem(), text); err != nil {
+			return err
+		}
+		v.Set(elem)
+	case reflect.String:
+		v.SetString(text)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type() == reflect.TypeOf(time.Duration(0)) {
+			d, err := time.ParseDuration(text)
+			if err != nil {
+				return err
+			}
+			v.SetInt(int64(d))
+			return nil
+		}
+		n, err := strconv.ParseInt(text, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(text, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported type %s", v.Type())
+		}
+		v.Set(reflect.ValueOf(splitList(text)).Convert(v.Type()))
+	case reflect.Map:
+		switch v.Type() {
+		case reflect.TypeOf(map[string]Level(nil)):
+			levels, err := parseLevelSpec(text)
+			if err != nil {
+				return err
+			}
+			v.Set(reflect.ValueOf(levels))
+		case reflect.TypeOf(map[string]any(nil)):
+			fields := make(map[string]any)
+			for _, item := range splitList(text) {
+				key, value, ok := strings.Cut(item, "=")
+				if !ok {
+					return fmt.Errorf("invalid field %q, want key=value", item)
+				}
+				fields[strings.TrimSpace(key)] = strings.TrimSpace(value)
+			}
+			v.Set(reflect.ValueOf(fields))
+		default:
+			return fmt.Errorf("unsupported type %s", v.Type())
+		}
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// splitList splits a comma-separated list, dropping empty items
+func splitList(text string) []string {
+	var items []string
+	for _, item := range strings.Split(text, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// fieldByTag returns the field of struct v whose yaml tag is name
+func fieldByTag(v reflect.Value, name string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if tagName(t.Field(i)) == name {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// tagName returns the yaml key of a struct field, or "" if it has none
+func tagName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// isNestedStruct reports whether t is a struct, or pointer to struct, whose
+// fields are configured individually
+func isNestedStruct(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == reflect.TypeOf(time.Time{}) {
+		return false
+	}
+	return !reflect.PointerTo(t).Implements(reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem())
+}
+
+// upperSnake converts a camelCase key to UPPER_SNAKE_CASE
+func upperSnake(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) && i > 0 {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
+
+// joinKey appends name to a dotted key path
+func joinKey(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// keyOrRoot names the config root when path is empty
+func keyOrRoot(path string) string {
+	if path == "" {
+		return "(root)"
+	}
+	return path
+}
@@ -0,0 +1,156 @@
+package golog
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	return path
+}
+
+func TestLoadConfigYAML(t *testing.T) {
+	path := writeConfigFile(t, "log.yaml", `
+level: WARNING
+encoding: console
+outputPaths: [stdout, /var/log/app.log]
+callerSkip: 1
+namedLevels:
+  billing.*: debug
+rotate:
+  maxSize: 50
+  compress: true
+`)
+
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if config.Level != WarnLevel || config.Encoding != "console" || config.CallerSkip != 1 {
+		t.Errorf("Unexpected config %+v", config)
+	}
+	if len(config.OutputPaths) != 2 || config.OutputPaths[1] != "/var/log/app.log" {
+		t.Errorf("Unexpected OutputPaths %v", config.OutputPaths)
+	}
+	if config.NamedLevels["billing.*"] != DebugLevel {
+		t.Errorf("Unexpected NamedLevels %v", config.NamedLevels)
+	}
+	if config.Rotate == nil || config.Rotate.MaxSize != 50 || !config.Rotate.Compress {
+		t.Errorf("Unexpected Rotate %+v", config.Rotate)
+	}
+	// Keys missing from the file keep their defaults
+	if len(config.ErrorOutputPaths) != 1 || config.ErrorOutputPaths[0] != "stderr" {
+		t.Errorf("Unexpected ErrorOutputPaths %v", config.ErrorOutputPaths)
+	}
+}
+
+func TestLoadConfigJSON(t *testing.T) {
+	path := writeConfigFile(t, "log.json", `{"level": "error", "development": true, "rotate": {"maxBackups": 3}}`)
+
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if config.Level != ErrorLevel || !config.Development || config.Encoding != "json" {
+		t.Errorf("Unexpected config %+v", config)
+	}
+	if config.Rotate == nil || config.Rotate.MaxBackups != 3 {
+		t.Errorf("Unexpected Rotate %+v", config.Rotate)
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	path := writeConfigFile(t, "log.yaml", `
+level: loud
+lvl: debug
+rotate:
+  maxSize: big
+`)
+
+	_, err := LoadConfig(path)
+	if err == nil {
+		t.Fatal("Expected error")
+	}
+
+	for _, key := range []string{"level", "lvl", "rotate.maxSize"} {
+		found := false
+		for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+			var configErr *ConfigError
+			if errors.As(e, &configErr) && configErr.Key == key {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Expected error for key %q in %v", key, err)
+		}
+	}
+
+	if _, err := LoadConfig(writeConfigFile(t, "log.toml", "")); err == nil {
+		t.Error("Expected error for unsupported extension")
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("GOLOG_LEVEL", "debug")
+	t.Setenv("GOLOG_ENCODING", "console")
+	t.Setenv("GOLOG_OUTPUT_PATHS", "stdout, /tmp/app.log")
+	t.Setenv("GOLOG_DISABLE_CALLER_TRIM", "true")
+	t.Setenv("GOLOG_NAMED_LEVELS", "billing.*=debug,db=warn")
+	t.Setenv("GOLOG_ROTATE_MAX_SIZE", "10")
+
+	config, err := ConfigFromEnv("GOLOG")
+	if err != nil {
+		t.Fatalf("ConfigFromEnv failed: %v", err)
+	}
+	if config.Level != DebugLevel || config.Encoding != "console" || !config.DisableCallerTrim {
+		t.Errorf("Unexpected config %+v", config)
+	}
+	if strings.Join(config.OutputPaths, ",") != "stdout,/tmp/app.log" {
+		t.Errorf("Unexpected OutputPaths %v", config.OutputPaths)
+	}
+	if config.NamedLevels["db"] != WarnLevel {
+		t.Errorf("Unexpected NamedLevels %v", config.NamedLevels)
+	}
+	if config.Rotate == nil || config.Rotate.MaxSize != 10 {
+		t.Errorf("Unexpected Rotate %+v", config.Rotate)
+	}
+}
+
+func TestConfigFromEnvErrors(t *testing.T) {
+	t.Setenv("GOLOG_LEVEL", "loud")
+	t.Setenv("GOLOG_CALLER_SKIP", "-1")
+
+	_, err := ConfigFromEnv("GOLOG")
+	if err == nil {
+		t.Fatal("Expected error")
+	}
+	for _, key := range []string{"GOLOG_LEVEL", "GOLOG_CALLER_SKIP"} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("Expected error to name %s, got %v", key, err)
+		}
+	}
+}
+
+func TestConfigFromEnvUnset(t *testing.T) {
+	config, err := ConfigFromEnv("GOLOG_TEST_UNSET")
+	if err != nil {
+		t.Fatalf("ConfigFromEnv failed: %v", err)
+	}
+	if config.Rotate != nil {
+		t.Errorf("Expected Rotate to stay nil, got %+v", config.Rotate)
+	}
+
+	logger, err := NewLoggerWithConfig(config)
+	if err != nil {
+		t.Fatalf("NewLoggerWithConfig failed: %v", err)
+	}
+	logger.Sync()
+}
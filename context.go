@@ -0,0 +1,212 @@
+package golog
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/muleiwu/gsr"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// loggerKey is the context key for the logger stored by NewContext
+type loggerKey struct{}
+
+// fieldsKey is the context key for the fields stored by ContextWithFields
+type fieldsKey struct{}
+
+// defaultLogger is the logger returned by Default
+var defaultLogger atomic.Pointer[Logger]
+
+// ContextExtractor returns fields derived from a context, such as trace or
+// request IDs, to add to entries logged with the Context methods. It is
+// called for every such entry and must be safe for concurrent use.
+type ContextExtractor func(ctx context.Context) []gsr.LoggerField
+
+// extractors holds the extractors added with RegisterContextExtractor
+var extractors atomic.Pointer[[]ContextExtractor]
+
+// NewContext returns a copy of ctx that carries l, to be retrieved with
+// FromContext further down the call chain
+//
+// Example:
+//
+//	requestLogger := logger.With(golog.Field("request_id", id))
+//	ctx := golog.NewContext(r.Context(), requestLogger)
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+// FromContext returns the logger stored in ctx by NewContext, or Default if
+// ctx carries none. Fields stored with ContextWithFields are not added; use
+// the Context logging methods for that.
+func FromContext(ctx context.Context) *Logger {
+	if l, ok := ctx.Value(loggerKey{}).(*Logger); ok && l != nil {
+		return l
+	}
+	return Default()
+}
+
+// ContextWithFields returns a copy of ctx carrying fields in addition to
+// those already in ctx. The Context logging methods, such as InfoContext,
+// add them to every entry.
+//
+// Example:
+//
+//	ctx = golog.ContextWithFields(ctx, golog.Field("user_id", user.ID))
+//	logger.InfoContext(ctx, "order placed")
+func ContextWithFields(ctx context.Context, fields ...gsr.LoggerField) context.Context {
+	if len(fields) == 0 {
+		return ctx
+	}
+	prev, _ := ctx.Value(fieldsKey{}).([]gsr.LoggerField)
+	all := make([]gsr.LoggerField, 0, len(prev)+len(fields))
+	all = append(append(all, prev...), fields...)
+	return context.WithValue(ctx, fieldsKey{}, all)
+}
+
+// SetDefault sets the logger returned by Default and by FromContext for
+// contexts without a logger
+func SetDefault(l *Logger) {
+	defaultLogger.Store(l)
+}
+
+// Default returns the logger set with SetDefault. Until SetDefault is
+// called, it returns a production logger writing JSON to stderr.
+func Default() *Logger {
+	if l := defaultLogger.Load(); l != nil {
+		return l
+	}
+	l, err := NewProductionLogger()
+	if err != nil {
+		l = NewLoggerWithZap(zap.NewNop())
+	}
+	defaultLogger.CompareAndSwap(nil, l)
+	return defaultLogger.Load()
+}
+
+// RegisterContextExtractor adds extractors whose fields are included in every
+// entry logged with the Context methods, by every logger. Register them
+// during program initialization.
+//
+// Example:
+//
+//	golog.RegisterContextExtractor(golog.TraceparentExtractor,
+//		func(ctx context.Context) []gsr.LoggerField {
+//			if tenant, ok := ctx.Value(tenantKey{}).(string); ok {
+//				return []gsr.LoggerField{golog.Field("tenant", tenant)}
+//			}
+//			return nil
+//		})
+func RegisterContextExtractor(extractor ...ContextExtractor) {
+	for {
+		prev := extractors.Load()
+		var all []ContextExtractor
+		if prev != nil {
+			all = append(all, *prev...)
+		}
+		all = append(all, extractor...)
+		if extractors.CompareAndSwap(prev, &all) {
+			return
+		}
+	}
+}
+
+// contextFields converts the fields from the registered extractors, the
+// fields stored in ctx and args, in that order, to zap fields. The Context
+// methods only call it for enabled entries, so extractors don't run for
+// entries that are dropped.
+func (l *Logger) contextFields(ctx context.Context, args []gsr.LoggerField) []zap.Field {
+	var fields []zap.Field
+	if registered := extractors.Load(); registered != nil {
+		for _, extract := range *registered {
+			fields = append(fields, l.getFields(extract(ctx)...)...)
+		}
+	}
+	if stored, _ := ctx.Value(fieldsKey{}).([]gsr.LoggerField); len(stored) > 0 {
+		fields = append(fields, l.getFields(stored...)...)
+	}
+	if fields == nil {
+		return l.getFields(args...)
+	}
+	return append(fields, l.getFields(args...)...)
+}
+
+// TraceContext logs a message at TraceLevel with the fields from ctx
+func (l *Logger) TraceContext(ctx context.Context, format string, args ...gsr.LoggerField) {
+	if ce := l.logger.Check(zapTraceLevel, format); ce != nil {
+		ce.Write(l.contextFields(ctx, args)...)
+	}
+}
+
+// DebugContext logs a message at DebugLevel with the fields from ctx
+func (l *Logger) DebugContext(ctx context.Context, format string, args ...gsr.LoggerField) {
+	if ce := l.logger.Check(zapcore.DebugLevel, format); ce != nil {
+		ce.Write(l.contextFields(ctx, args)...)
+	}
+}
+
+// InfoContext logs a message at InfoLevel with the fields from ctx
+func (l *Logger) InfoContext(ctx context.Context, format string, args ...gsr.LoggerField) {
+	if ce := l.logger.Check(zapcore.InfoLevel, format); ce != nil {
+		ce.Write(l.contextFields(ctx, args)...)
+	}
+}
+
+// NoticeContext logs a message at NoticeLevel with the fields from ctx
+func (l *Logger) NoticeContext(ctx context.Context, format string, args ...gsr.LoggerField) {
+	if ce := l.logger.Check(zapNoticeLevel, format); ce != nil {
+		ce.Write(l.contextFields(ctx, args)...)
+	}
+}
+
+// WarnContext logs a message at WarnLevel with the fields from ctx
+func (l *Logger) WarnContext(ctx context.Context, format string, args ...gsr.LoggerField) {
+	if ce := l.logger.Check(zapcore.WarnLevel, format); ce != nil {
+		ce.Write(l.contextFields(ctx, args)...)
+	}
+}
+
+// ErrorContext logs a message at ErrorLevel with the fields from ctx
+func (l *Logger) ErrorContext(ctx context.Context, format string, args ...gsr.LoggerField) {
+	if ce := l.logger.Check(zapcore.ErrorLevel, format); ce != nil {
+		ce.Write(l.contextFields(ctx, args)...)
+	}
+}
+
+// CriticalContext logs a message at CriticalLevel with the fields from ctx
+func (l *Logger) CriticalContext(ctx context.Context, format string, args ...gsr.LoggerField) {
+	if ce := l.logger.Check(zapCriticalLevel, format); ce != nil {
+		ce.Write(l.contextFields(ctx, args)...)
+	}
+}
+
+// AlertContext logs a message at AlertLevel with the fields from ctx
+func (l *Logger) AlertContext(ctx context.Context, format string, args ...gsr.LoggerField) {
+	if ce := l.logger.Check(zapAlertLevel, format); ce != nil {
+		ce.Write(l.contextFields(ctx, args)...)
+	}
+}
+
+// EmergencyContext logs a message at EmergencyLevel with the fields from ctx
+func (l *Logger) EmergencyContext(ctx context.Context, format string, args ...gsr.LoggerField) {
+	if ce := l.logger.Check(zapEmergencyLevel, format); ce != nil {
+		ce.Write(l.contextFields(ctx, args)...)
+	}
+}
+
+// FatalContext logs a message at FatalLevel with the fields from ctx
+// and then calls os.Exit(1)
+func (l *Logger) FatalContext(ctx context.Context, format string, args ...gsr.LoggerField) {
+	if ce := l.logger.Check(zapcore.FatalLevel, format); ce != nil {
+		ce.Write(l.contextFields(ctx, args)...)
+	}
+}
+
+// PanicContext logs a message at PanicLevel with the fields from ctx
+// and then panics
+func (l *Logger) PanicContext(ctx context.Context, format string, args ...gsr.LoggerField) {
+	if ce := l.logger.Check(zapcore.PanicLevel, format); ce != nil {
+		ce.Write(l.contextFields(ctx, args)...)
+	}
+}
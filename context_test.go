@@ -0,0 +1,145 @@
+package golog
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/muleiwu/gsr"
+	"go.uber.org/zap"
+)
+
+func TestFromContext(t *testing.T) {
+	logger, logs := newObservedLogger(InfoLevel)
+	requestLogger := logger.With(Field("request_id", "abc"))
+
+	ctx := NewContext(context.Background(), requestLogger)
+	if got := FromContext(ctx); got != requestLogger {
+		t.Fatalf("Expected the stored logger, got %p", got)
+	}
+	FromContext(ctx).Info("handled")
+
+	entries := logs.All()
+	if len(entries) != 1 || entries[0].ContextMap()["request_id"] != "abc" {
+		t.Errorf("Expected an entry with request_id, got %v", entries)
+	}
+}
+
+func TestFromContextDefault(t *testing.T) {
+	t.Cleanup(func() { defaultLogger.Store(nil) })
+
+	if FromContext(context.Background()) != Default() || Default() == nil {
+		t.Fatal("Expected FromContext to fall back to Default")
+	}
+
+	logger, _ := newObservedLogger(InfoLevel)
+	SetDefault(logger)
+	if FromContext(context.Background()) != logger {
+		t.Error("Expected FromContext to return the logger set with SetDefault")
+	}
+	if FromContext(NewContext(context.Background(), nil)) != logger {
+		t.Error("Expected a nil stored logger to fall back to Default")
+	}
+}
+
+func TestContextMethods(t *testing.T) {
+	logger, logs := newObservedLogger(TraceLevel)
+
+	ctx := ContextWithFields(context.Background(), Field("user_id", 42))
+	ctx = ContextWithFields(ctx, Field("tenant", "acme"))
+	if ContextWithFields(ctx) != ctx {
+		t.Error("Expected ContextWithFields without fields to return ctx")
+	}
+
+	logger.TraceContext(ctx, "trace")
+	logger.DebugContext(ctx, "debug")
+	logger.InfoContext(ctx, "info", Field("extra", true))
+	logger.NoticeContext(ctx, "notice")
+	logger.WarnContext(ctx, "warn")
+	logger.ErrorContext(ctx, "error")
+	logger.CriticalContext(ctx, "critical")
+	logger.AlertContext(ctx, "alert")
+	logger.EmergencyContext(ctx, "emergency")
+
+	entries := logs.All()
+	want := []Level{TraceLevel, DebugLevel, InfoLevel, NoticeLevel, WarnLevel, ErrorLevel, CriticalLevel, AlertLevel, EmergencyLevel}
+	if len(entries) != len(want) {
+		t.Fatalf("Expected %d entries, got %d", len(want), len(entries))
+	}
+	for i, entry := range entries {
+		if got := fromZapLevel(entry.Level); got != want[i] {
+			t.Errorf("Entry %d: expected level %v, got %v", i, want[i], got)
+		}
+		fields := entry.ContextMap()
+		if fields["user_id"] != int64(42) || fields["tenant"] != "acme" {
+			t.Errorf("Entry %d: expected context fields, got %v", i, fields)
+		}
+	}
+	if entries[2].ContextMap()["extra"] != true {
+		t.Errorf("Expected explicit fields too, got %v", entries[2].ContextMap())
+	}
+}
+
+func TestContextMethodsCaller(t *testing.T) {
+	logger, logs := newObservedLogger(TraceLevel, zap.AddCaller(), zap.AddCallerSkip(1))
+
+	logger.InfoContext(context.Background(), "hello")
+
+	entries := logs.All()
+	if len(entries) != 1 || filepath.Base(entries[0].Caller.File) != "context_test.go" {
+		t.Errorf("Expected the caller to be context_test.go, got %v", entries)
+	}
+}
+
+func TestPanicContext(t *testing.T) {
+	logger, logs := newObservedLogger(InfoLevel)
+	ctx := ContextWithFields(context.Background(), Field("job", "sync"))
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Expected PanicContext to panic")
+		}
+		if entries := logs.All(); len(entries) != 1 || entries[0].ContextMap()["job"] != "sync" {
+			t.Errorf("Expected a panic entry with context fields, got %v", entries)
+		}
+	}()
+	logger.PanicContext(ctx, "boom")
+}
+
+func TestContextExtractors(t *testing.T) {
+	t.Cleanup(func() { extractors.Store(nil) })
+	type tenantKey struct{}
+	calls := 0
+	RegisterContextExtractor(func(ctx context.Context) []gsr.LoggerField {
+		calls++
+		if tenant, ok := ctx.Value(tenantKey{}).(string); ok {
+			return []gsr.LoggerField{Field("tenant", tenant)}
+		}
+		return nil
+	})
+	RegisterContextExtractor(func(ctx context.Context) []gsr.LoggerField {
+		return []gsr.LoggerField{Field("request_id", "req-1")}
+	})
+	logger, logs := newObservedLogger(InfoLevel)
+
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+	ctx = ContextWithFields(ctx, Field("user_id", 42))
+	logger.InfoContext(ctx, "hello", Field("extra", true))
+	logger.DebugContext(ctx, "dropped")
+
+	entries := logs.All()
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 entry, got %d", len(entries))
+	}
+	var keys []string
+	for _, f := range entries[0].Context {
+		keys = append(keys, f.Key)
+	}
+	if got, want := strings.Join(keys, ","), "tenant,request_id,user_id,extra"; got != want {
+		t.Errorf("Expected fields %s, got %s", want, got)
+	}
+	if calls != 1 {
+		t.Errorf("Expected extractors to run only for enabled entries, ran %d times", calls)
+	}
+}
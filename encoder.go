@@ -0,0 +1,225 @@
+package golog
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// EncoderConfig sets the keys and value formats of encoded entries. Empty
+// fields keep the defaults of the production or development preset chosen by
+// Config.Development; set a key to "-" to leave it out of the output.
+//
+// Example, for a pipeline expecting Elastic-style entries:
+//
+//	config.Encoder = golog.EncoderConfig{
+//		TimeKey:      "@timestamp",
+//		MessageKey:   "message",
+//		LevelKey:     "severity",
+//		TimeEncoding: "rfc3339nano",
+//		TimeZone:     "UTC",
+//	}
+type EncoderConfig struct {
+	// MessageKey is the key for the log message. Default: "msg" ("M" in development)
+	MessageKey string `json:"messageKey" yaml:"messageKey"`
+	// LevelKey is the key for the level. Default: "level" ("L" in development)
+	LevelKey string `json:"levelKey" yaml:"levelKey"`
+	// TimeKey is the key for the time. Default: "ts" ("T" in development)
+	TimeKey string `json:"timeKey" yaml:"timeKey"`
+	// NameKey is the key for the logger name. Default: "logger" ("N" in development)
+	NameKey string `json:"nameKey" yaml:"nameKey"`
+	// CallerKey is the key for the caller. Default: "caller" ("C" in development)
+	CallerKey string `json:"callerKey" yaml:"callerKey"`
+	// FunctionKey is the key for the calling function. Default: omitted
+	FunctionKey string `json:"functionKey" yaml:"functionKey"`
+	// StacktraceKey is the key for stack traces. Default: "stacktrace" ("S" in development)
+	StacktraceKey string `json:"stacktraceKey" yaml:"stacktraceKey"`
+
+	// TimeEncoding is one of "epoch" (float seconds), "millis", "nanos",
+	// "iso8601", "rfc3339" or "rfc3339nano", or a Go time layout such as
+	// "2006-01-02 15:04:05.000". Default: "epoch" ("iso8601" in development)
+	TimeEncoding string `json:"timeEncoding" yaml:"timeEncoding"`
+	// TimeZone is an IANA time zone name such as "UTC" or "Asia/Shanghai",
+	// or "Local". Default: local time
+	TimeZone string `json:"timeZone" yaml:"timeZone"`
+	// DurationEncoding is one of "seconds" (float), "millis", "nanos" or
+	// "string" (e.g. "1.5s"). Default: "seconds" ("string" in development)
+	DurationEncoding string `json:"durationEncoding" yaml:"durationEncoding"`
+	// LevelEncoding is one of "lowercase", "capital", "lowercaseColor" or
+	// "capitalColor". Default: "lowercase" ("capital" in development).
+	// OutputConfig.Color switches to the colored variant.
+	LevelEncoding string `json:"levelEncoding" yaml:"levelEncoding"`
+}
+
+// newOutputEncoder creates the encoder for out
+func newOutputEncoder(config Config, out OutputConfig) (zapcore.Encoder, error) {
+	encoderConfig, err := config.Encoder.build(config.Development, out.Color)
+	if err != nil {
+		return nil, err
+	}
+
+	// Configure caller encoder based on DisableCallerTrim
+	if config.DisableCallerTrim {
+		encoderConfig.EncodeCaller = zapcore.FullCallerEncoder
+	}
+
+	return newEncoder(out.Encoding, encoderConfig)
+}
+
+// build returns the zapcore.EncoderConfig described by c, starting from the
+// development or production preset
+func (c EncoderConfig) build(development, color bool) (zapcore.EncoderConfig, error) {
+	// Create encoder config based on development mode
+	var encoderConfig zapcore.EncoderConfig
+	levelEncoding := "lowercase"
+	if development {
+		encoderConfig = zap.NewDevelopmentEncoderConfig()
+		levelEncoding = "capital"
+	} else {
+		encoderConfig = zap.NewProductionEncoderConfig()
+	}
+
+	setKey(&encoderConfig.MessageKey, c.MessageKey)
+	setKey(&encoderConfig.LevelKey, c.LevelKey)
+	setKey(&encoderConfig.TimeKey, c.TimeKey)
+	setKey(&encoderConfig.NameKey, c.NameKey)
+	setKey(&encoderConfig.CallerKey, c.CallerKey)
+	setKey(&encoderConfig.FunctionKey, c.FunctionKey)
+	setKey(&encoderConfig.StacktraceKey, c.StacktraceKey)
+
+	if c.LevelEncoding != "" {
+		levelEncoding = c.LevelEncoding
+	}
+	encodeLevel, err := parseLevelEncoding(levelEncoding, color)
+	if err != nil {
+		return encoderConfig, err
+	}
+	encoderConfig.EncodeLevel = encodeLevel
+
+	if c.TimeEncoding != "" {
+		if encoderConfig.EncodeTime, err = parseTimeEncoding(c.TimeEncoding); err != nil {
+			return encoderConfig, err
+		}
+	}
+	if c.TimeZone != "" {
+		loc, err := time.LoadLocation(c.TimeZone)
+		if err != nil {
+			return encoderConfig, fmt.Errorf("golog: unknown time zone %q: %w", c.TimeZone, err)
+		}
+		encodeTime := encoderConfig.EncodeTime
+		encoderConfig.EncodeTime = func(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
+			encodeTime(t.In(loc), enc)
+		}
+	}
+
+	if c.DurationEncoding != "" {
+		if encoderConfig.EncodeDuration, err = parseDurationEncoding(c.DurationEncoding); err != nil {
+			return encoderConfig, err
+		}
+	}
+
+	return encoderConfig, nil
+}
+
+// setKey sets *dst to key unless key is empty; "-" omits the key
+func setKey(dst *string, key string) {
+	switch key {
+	case "":
+	case "-":
+		*dst = zapcore.OmitKey
+	default:
+		*dst = key
+	}
+}
+
+// parseLevelEncoding returns the level encoder named by name, or its colored
+// variant if color is set
+func parseLevelEncoding(name string, color bool) (zapcore.LevelEncoder, error) {
+	switch strings.ToLower(name) {
+	case "lowercase":
+		if color {
+			return LowercaseColorLevelEncoder, nil
+		}
+		return LowercaseLevelEncoder, nil
+	case "capital":
+		if color {
+			return CapitalColorLevelEncoder, nil
+		}
+		return CapitalLevelEncoder, nil
+	case "lowercasecolor", "color":
+		return LowercaseColorLevelEncoder, nil
+	case "capitalcolor":
+		return CapitalColorLevelEncoder, nil
+	default:
+		return nil, fmt.Errorf("golog: unknown level encoding %q", name)
+	}
+}
+
+// parseTimeEncoding returns the time encoder named by name, treating
+// anything else as a time layout
+func parseTimeEncoding(name string) (zapcore.TimeEncoder, error) {
+	switch strings.ToLower(name) {
+	case "epoch":
+		return zapcore.EpochTimeEncoder, nil
+	case "millis":
+		return zapcore.EpochMillisTimeEncoder, nil
+	case "nanos":
+		return zapcore.EpochNanosTimeEncoder, nil
+	case "iso8601":
+		return zapcore.ISO8601TimeEncoder, nil
+	case "rfc3339":
+		return zapcore.RFC3339TimeEncoder, nil
+	case "rfc3339nano":
+		return zapcore.RFC3339NanoTimeEncoder, nil
+	}
+
+	// A layout must contain at least one element that formatting replaces
+	ref := time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)
+	if ref.Format(name) == name {
+		return nil, fmt.Errorf("golog: unknown time encoding %q", name)
+	}
+	return zapcore.TimeEncoderOfLayout(name), nil
+}
+
+// parseDurationEncoding returns the duration encoder named by name
+func parseDurationEncoding(name string) (zapcore.DurationEncoder, error) {
+	switch strings.ToLower(name) {
+	case "seconds":
+		return zapcore.SecondsDurationEncoder, nil
+	case "millis":
+		return zapcore.MillisDurationEncoder, nil
+	case "nanos":
+		return zapcore.NanosDurationEncoder, nil
+	case "string":
+		return zapcore.StringDurationEncoder, nil
+	default:
+		return nil, fmt.Errorf("golog: unknown duration encoding %q", name)
+	}
+}
+
+// validate adds an error to errs for every invalid setting in c
+func (c EncoderConfig) validate(key string, errs *ConfigErrors) {
+	if c.LevelEncoding != "" {
+		if _, err := parseLevelEncoding(c.LevelEncoding, false); err != nil {
+			errs.add(joinKey(key, "levelEncoding"), err)
+		}
+	}
+	if c.TimeEncoding != "" {
+		if _, err := parseTimeEncoding(c.TimeEncoding); err != nil {
+			errs.add(joinKey(key, "timeEncoding"), err)
+		}
+	}
+	if c.TimeZone != "" {
+		if _, err := time.LoadLocation(c.TimeZone); err != nil {
+			errs.add(joinKey(key, "timeZone"), err)
+		}
+	}
+	if c.DurationEncoding != "" {
+		if _, err := parseDurationEncoding(c.DurationEncoding); err != nil {
+			errs.add(joinKey(key, "durationEncoding"), err)
+		}
+	}
+}
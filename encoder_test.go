@@ -0,0 +1,102 @@
+package golog
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestEncoderConfig(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "app.log")
+	config := DefaultConfig()
+	config.OutputPaths = []string{filename}
+	config.Encoder = EncoderConfig{
+		TimeKey:          "@timestamp",
+		MessageKey:       "message",
+		LevelKey:         "severity",
+		CallerKey:        "-",
+		TimeEncoding:     "rfc3339nano",
+		TimeZone:         "UTC",
+		DurationEncoding: "string",
+		LevelEncoding:    "capital",
+	}
+	logger := newFileLogger(t, config)
+
+	logger.Warn("hello", Field("elapsed", 1500*time.Millisecond))
+	logger.Sync()
+
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(readFile(t, filename)), &entry); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if entry["message"] != "hello" || entry["severity"] != "WARN" || entry["elapsed"] != "1.5s" {
+		t.Errorf("Unexpected entry %v", entry)
+	}
+	if _, ok := entry["caller"]; ok {
+		t.Errorf("Expected caller to be omitted, got %v", entry)
+	}
+	ts, _ := entry["@timestamp"].(string)
+	if parsed, err := time.Parse(time.RFC3339Nano, ts); err != nil || !strings.HasSuffix(ts, "Z") || time.Since(parsed) > time.Minute {
+		t.Errorf("Expected an RFC3339Nano UTC timestamp, got %q", ts)
+	}
+}
+
+func TestEncoderConfigLayout(t *testing.T) {
+	encoderConfig, err := EncoderConfig{TimeEncoding: "2006-01-02 15:04:05", TimeZone: "Asia/Shanghai"}.build(false, true)
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+
+	buf, err := zapcore.NewConsoleEncoder(encoderConfig).EncodeEntry(zapcore.Entry{
+		Time:    time.Date(2024, 2, 10, 0, 30, 0, 0, time.UTC),
+		Level:   zapTraceLevel,
+		Message: "hello",
+	}, nil)
+	if err != nil {
+		t.Fatalf("EncodeEntry failed: %v", err)
+	}
+	if got, want := buf.String(), "2024-02-10 08:30:00\t"+colorMagenta+"trace"+colorReset+"\thello\n"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestEncoderConfigErrors(t *testing.T) {
+	config := DefaultConfig()
+	config.Encoder = EncoderConfig{
+		TimeEncoding:     "yesterday",
+		TimeZone:         "Mars/Olympus",
+		DurationEncoding: "fortnights",
+		LevelEncoding:    "shouting",
+	}
+
+	var errs ConfigErrors
+	if !errors.As(config.Validate(), &errs) {
+		t.Fatal("Expected ConfigErrors")
+	}
+	var keys []string
+	for _, e := range errs {
+		keys = append(keys, e.Key)
+	}
+	want := "encoder.levelEncoding,encoder.timeEncoding,encoder.timeZone,encoder.durationEncoding"
+	if got := strings.Join(keys, ","); got != want {
+		t.Errorf("Expected errors for %s, got %s", want, got)
+	}
+}
+
+func TestEncoderConfigFromEnv(t *testing.T) {
+	t.Setenv("GOLOG_ENCODER_MESSAGE_KEY", "message")
+	t.Setenv("GOLOG_ENCODER_TIME_ENCODING", "rfc3339")
+
+	config, err := ConfigFromEnv("GOLOG")
+	if err != nil {
+		t.Fatalf("ConfigFromEnv failed: %v", err)
+	}
+	if config.Encoder.MessageKey != "message" || config.Encoder.TimeEncoding != "rfc3339" {
+		t.Errorf("Unexpected encoder config %+v", config.Encoder)
+	}
+}
@@ -1,8 +1,8 @@
 package main
 
 import (
+	"context"
 	"net/http"
-	"time"
 
 	"github.com/muleiwu/golog"
 )
@@ -21,32 +21,37 @@ func main() {
 	}
 	defer logger.Sync()
 
-	// HTTP handler with logging
-	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		start := time.Now()
+	// Add trace IDs from incoming traceparent headers to request logs
+	golog.RegisterContextExtractor(golog.TraceparentExtractor)
 
-		// Create a request-scoped logger
-		requestLogger := logger.With(
-			golog.Field("method", r.Method),
-			golog.Field("path", r.URL.Path),
-			golog.Field("remote_addr", r.RemoteAddr),
-		)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		// The middleware stores a request-scoped logger with the request ID,
+		// method and path in the context
+		golog.FromContext(r.Context()).Info("Request received")
 
-		requestLogger.Info("Request received")
-
-		// Simulate some processing
-		w.Write([]byte("Hello, World!"))
-
-		// Log completion with duration
-		requestLogger.Info("Request completed",
-			golog.Field("duration_ms", time.Since(start).Milliseconds()),
-			golog.Field("status", http.StatusOK),
-		)
+		w.Write([]byte(greeting(r.Context())))
+	})
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
 	})
 
+	// Log one access line per request with status, bytes and latency,
+	// skipping health checks. Panics in handlers are logged with their stack
+	// and answered with 500 instead of bypassing the logger.
+	handler := logger.HTTPMiddleware(golog.HTTPConfig{
+		Skip: func(r *http.Request) bool { return r.URL.Path == "/healthz" },
+	})(logger.RecoveryMiddleware(golog.ErrorLevel)(mux))
+
 	logger.Info("Starting HTTP server", golog.Field("port", 8080))
 
-	if err := http.ListenAndServe(":8080", nil); err != nil {
+	if err := http.ListenAndServe(":8080", handler); err != nil {
 		logger.Fatal("Failed to start server", golog.Field("error", err))
 	}
 }
+
+// greeting builds the response body, logging through the request's logger
+func greeting(ctx context.Context) string {
+	golog.FromContext(ctx).Debug("Building greeting")
+	return "Hello, World!"
+}
@@ -0,0 +1,179 @@
+package golog
+
+import (
+	"flag"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// RegisterFlags defines command-line flags on fs that set the fields of c,
+// with c's current values as defaults. Flag names start with prefix and a
+// period, so with prefix "log":
+//
+//	-log.level               Level
+//	-log.encoding            Encoding
+//	-log.development         Development
+//	-log.output              OutputPaths, comma-separated
+//	-log.error-output        ErrorOutputPaths, comma-separated
+//	-log.split-stderr        SplitStderr
+//	-log.caller-skip         CallerSkip
+//	-log.caller-trim         the inverse of DisableCallerTrim
+//	-log.stacktrace          the inverse of DisableStacktrace
+//	-log.stacktrace-level    StacktraceLevel
+//	-log.stacktrace-depth    StacktraceDepth
+//	-log.v                   Verbosity
+//	-log.vmodule             VModule
+//	-log.named-levels        NamedLevels, e.g. billing.*=debug,db=warn
+//	-log.rotate.max-size     Rotate.MaxSize, and likewise max-age,
+//	                         max-backups, max-total-size, compress and local-time
+//
+// Setting any -log.rotate flag enables rotation. If fs is nil,
+// flag.CommandLine is used.
+//
+// Example:
+//
+//	config := golog.DefaultConfig()
+//	config.RegisterFlags(flag.CommandLine, "log")
+//	flag.Parse()
+//	logger, err := golog.NewLoggerWithConfig(config)
+func (c *Config) RegisterFlags(fs *flag.FlagSet, prefix string) {
+	if fs == nil {
+		fs = flag.CommandLine
+	}
+	if prefix != "" && !strings.HasSuffix(prefix, ".") {
+		prefix += "."
+	}
+
+	fs.Var(&c.Level, prefix+"level", "minimum enabled log level (trace, debug, info, notice, warn, error, ...)")
+	fs.StringVar(&c.Encoding, prefix+"encoding", c.Encoding, "log encoding (json, console or combined)")
+	fs.BoolVar(&c.Development, prefix+"development", c.Development, "log in development mode")
+	fs.Var(listFlag{&c.OutputPaths}, prefix+"output", "comma-separated list of log output paths or URLs")
+	fs.Var(listFlag{&c.ErrorOutputPaths}, prefix+"error-output", "comma-separated list of output paths for internal logger errors")
+	fs.BoolVar(&c.SplitStderr, prefix+"split-stderr", c.SplitStderr, "log warnings and above to stderr and everything else to stdout")
+	fs.UintVar(&c.CallerSkip, prefix+"caller-skip", c.CallerSkip, "number of wrapper frames to skip when annotating the caller")
+	fs.Var(invertedBoolFlag{&c.DisableCallerTrim}, prefix+"caller-trim", "shorten caller paths to the package directory and file")
+	fs.Var(invertedBoolFlag{&c.DisableStacktrace}, prefix+"stacktrace", "capture stack traces")
+	fs.Func(prefix+"stacktrace-level", "lowest log level that captures a stack trace (default error, warn in development)", func(text string) error {
+		level, err := ParseLevel(text)
+		if err != nil {
+			return err
+		}
+		c.StacktraceLevel = &level
+		return nil
+	})
+	fs.IntVar(&c.StacktraceDepth, prefix+"stacktrace-depth", c.StacktraceDepth, "maximum number of stack trace frames, 0 for no limit")
+	fs.IntVar(&c.Verbosity, prefix+"v", c.Verbosity, "global verbosity for V logging")
+	fs.StringVar(&c.VModule, prefix+"vmodule", c.VModule, "comma-separated list of pattern=N verbosity levels per source file")
+	fs.Var(levelSpecFlag{&c.NamedLevels}, prefix+"named-levels", "comma-separated list of pattern=level overrides for named loggers")
+
+	rotateInt := func(name, usage string, field func(*RotateConfig) *int) {
+		fs.Func(prefix+"rotate."+name, usage, func(text string) error {
+			n, err := strconv.Atoi(text)
+			if err != nil {
+				return err
+			}
+			*field(c.rotate()) = n
+			return nil
+		})
+	}
+	rotateBool := func(name, usage string, field func(*RotateConfig) *bool) {
+		fs.BoolFunc(prefix+"rotate."+name, usage, func(text string) error {
+			b, err := strconv.ParseBool(text)
+			if err != nil {
+				return err
+			}
+			*field(c.rotate()) = b
+			return nil
+		})
+	}
+	rotateInt("max-size", "rotate log files after they reach this many megabytes",
+		func(r *RotateConfig) *int { return &r.MaxSize })
+	rotateInt("max-age", "remove rotated log files older than this many days",
+		func(r *RotateConfig) *int { return &r.MaxAge })
+	rotateInt("max-backups", "maximum number of rotated log files to keep",
+		func(r *RotateConfig) *int { return &r.MaxBackups })
+	rotateInt("max-total-size", "maximum total megabytes of rotated log files to keep",
+		func(r *RotateConfig) *int { return &r.MaxTotalSize })
+	rotateBool("compress", "gzip rotated log files",
+		func(r *RotateConfig) *bool { return &r.Compress })
+	rotateBool("local-time", "name rotated log files using local time instead of UTC",
+		func(r *RotateConfig) *bool { return &r.LocalTime })
+}
+
+// rotate returns c.Rotate, allocating it if needed
+func (c *Config) rotate() *RotateConfig {
+	if c.Rotate == nil {
+		c.Rotate = &RotateConfig{}
+	}
+	return c.Rotate
+}
+
+// listFlag is a flag.Value for a comma-separated list of strings
+type listFlag struct {
+	p *[]string
+}
+
+func (f listFlag) String() string {
+	if f.p == nil {
+		return ""
+	}
+	return strings.Join(*f.p, ",")
+}
+
+func (f listFlag) Set(text string) error {
+	*f.p = splitList(text)
+	return nil
+}
+
+// invertedBoolFlag is a boolean flag.Value that stores the opposite of its value
+type invertedBoolFlag struct {
+	p *bool
+}
+
+func (f invertedBoolFlag) String() string {
+	if f.p == nil {
+		return "false"
+	}
+	return strconv.FormatBool(!*f.p)
+}
+
+func (f invertedBoolFlag) Set(text string) error {
+	b, err := strconv.ParseBool(text)
+	if err != nil {
+		return err
+	}
+	*f.p = !b
+	return nil
+}
+
+func (f invertedBoolFlag) IsBoolFlag() bool {
+	return true
+}
+
+// levelSpecFlag is a flag.Value for a comma-separated list of pattern=level
+// overrides, parsed by parseLevelSpec
+type levelSpecFlag struct {
+	p *map[string]Level
+}
+
+func (f levelSpecFlag) String() string {
+	if f.p == nil {
+		return ""
+	}
+	items := make([]string, 0, len(*f.p))
+	for pattern, level := range *f.p {
+		items = append(items, pattern+"="+level.String())
+	}
+	sort.Strings(items)
+	return strings.Join(items, ",")
+}
+
+func (f levelSpecFlag) Set(text string) error {
+	levels, err := parseLevelSpec(text)
+	if err != nil {
+		return err
+	}
+	*f.p = levels
+	return nil
+}
@@ -0,0 +1,93 @@
+package golog
+
+import (
+	"bytes"
+	"flag"
+	"strings"
+	"testing"
+)
+
+func TestRegisterFlags(t *testing.T) {
+	config := DefaultConfig()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	config.RegisterFlags(fs, "log")
+
+	err := fs.Parse([]string{
+		"-log.level=debug",
+		"-log.encoding", "console",
+		"-log.output", "stdout, /var/log/app.log",
+		"-log.caller-trim=false",
+		"-log.caller-skip=2",
+		"-log.stacktrace=false",
+		"-log.stacktrace-level=warn",
+		"-log.stacktrace-depth=5",
+		"-log.v=3",
+		"-log.vmodule", "db=2",
+		"-log.named-levels", "billing.*=trace,db=warn",
+		"-log.rotate.max-size=50",
+		"-log.rotate.compress",
+	})
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if config.Level != DebugLevel || config.Encoding != "console" {
+		t.Errorf("Unexpected level %v and encoding %q", config.Level, config.Encoding)
+	}
+	if strings.Join(config.OutputPaths, ",") != "stdout,/var/log/app.log" {
+		t.Errorf("Unexpected OutputPaths %v", config.OutputPaths)
+	}
+	if strings.Join(config.ErrorOutputPaths, ",") != "stderr" {
+		t.Errorf("Expected default ErrorOutputPaths to be kept, got %v", config.ErrorOutputPaths)
+	}
+	if !config.DisableCallerTrim || config.CallerSkip != 2 {
+		t.Errorf("Unexpected DisableCallerTrim %v and CallerSkip %d", config.DisableCallerTrim, config.CallerSkip)
+	}
+	if !config.DisableStacktrace || config.StacktraceLevel == nil || *config.StacktraceLevel != WarnLevel || config.StacktraceDepth != 5 {
+		t.Errorf("Unexpected stacktrace settings %v, %v and %d", config.DisableStacktrace, config.StacktraceLevel, config.StacktraceDepth)
+	}
+	if config.Verbosity != 3 || config.VModule != "db=2" {
+		t.Errorf("Unexpected Verbosity %d and VModule %q", config.Verbosity, config.VModule)
+	}
+	if config.NamedLevels["billing.*"] != TraceLevel || config.NamedLevels["db"] != WarnLevel {
+		t.Errorf("Unexpected NamedLevels %v", config.NamedLevels)
+	}
+	if config.Rotate == nil || config.Rotate.MaxSize != 50 || !config.Rotate.Compress {
+		t.Errorf("Unexpected Rotate %+v", config.Rotate)
+	}
+}
+
+func TestRegisterFlagsDefaults(t *testing.T) {
+	config := DefaultConfig()
+	config.Level = WarnLevel
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	config.RegisterFlags(fs, "")
+
+	if err := fs.Parse(nil); err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if config.Rotate != nil {
+		t.Errorf("Expected rotation to stay disabled, got %+v", config.Rotate)
+	}
+
+	var usage bytes.Buffer
+	fs.SetOutput(&usage)
+	fs.PrintDefaults()
+	for _, want := range []string{"-level value", `(default warn)`, "-caller-trim", "(default true)", `(default "json")`} {
+		if !strings.Contains(usage.String(), want) {
+			t.Errorf("Expected usage to contain %q, got:\n%s", want, usage.String())
+		}
+	}
+}
+
+func TestRegisterFlagsErrors(t *testing.T) {
+	for _, arg := range []string{"-log.level=loud", "-log.stacktrace-level=loud", "-log.named-levels=db", "-log.rotate.max-size=big"} {
+		config := DefaultConfig()
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.SetOutput(&bytes.Buffer{})
+		config.RegisterFlags(fs, "log")
+		if err := fs.Parse([]string{arg}); err == nil {
+			t.Errorf("Expected error parsing %s", arg)
+		}
+	}
+}
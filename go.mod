@@ -5,6 +5,7 @@ go 1.25.0
 require (
 	github.com/muleiwu/gsr v1.0.0
 	go.uber.org/zap v1.27.1
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require go.uber.org/multierr v1.10.0 // indirect
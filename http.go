@@ -0,0 +1,224 @@
+package golog
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/muleiwu/gsr"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// DefaultRequestIDHeader is the header HTTPMiddleware reads and sets request
+// IDs in unless HTTPConfig.RequestIDHeader is set
+const DefaultRequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds incoming request IDs; longer ones are replaced
+const maxRequestIDLength = 128
+
+// requestIDKey is the context key for the request ID stored by HTTPMiddleware
+type requestIDKey struct{}
+
+// HTTPConfig configures HTTPMiddleware. The zero value is ready to use.
+type HTTPConfig struct {
+	// RequestIDHeader is the header an incoming request ID is read from and
+	// the response's request ID is written to. Default: X-Request-ID
+	RequestIDHeader string
+	// GenerateRequestID returns an ID for requests that arrive without a
+	// valid one. Default: 26 random base32 characters
+	GenerateRequestID func() string
+	// StatusLevels sets the level of the access line per status class, keyed
+	// by the first digit of the status code. Classes not listed use the
+	// defaults: Warn for 4xx, Error for 5xx and Info otherwise.
+	StatusLevels map[int]Level
+	// Message is the message of the access line. Default: "http request"
+	Message string
+	// Skip, if set, suppresses the access line for requests it returns true
+	// for, such as health checks. They still get a request ID and logger.
+	Skip func(r *http.Request) bool
+}
+
+// HTTPMiddleware returns net/http middleware that gives every request a
+// request ID and a request-scoped logger, and logs one access line when the
+// handler returns.
+//
+// The request ID is taken from the X-Request-ID header, or generated if the
+// header is missing or invalid, and is echoed in the response header. The
+// request-scoped logger is l with "request_id", "method" and "path" fields;
+// handlers retrieve it with FromContext(r.Context()). A W3C traceparent
+// header is stored with ContextWithTraceparent.
+//
+// The access line adds "status", "bytes", "latency", "remote_addr", "proto"
+// and "user_agent", "query" and "referer" when present, and the fields of
+// the registered context extractors, and is logged at the level configured
+// for the status class, without a caller or stack trace. An output with the "combined"
+// encoding writes it in the Apache combined log format.
+//
+// Example:
+//
+//	mux := http.NewServeMux()
+//	mux.HandleFunc("/orders", func(w http.ResponseWriter, r *http.Request) {
+//		golog.FromContext(r.Context()).Info("listing orders")
+//	})
+//	http.ListenAndServe(":8080", logger.HTTPMiddleware(golog.HTTPConfig{})(mux))
+func (l *Logger) HTTPMiddleware(config HTTPConfig) func(http.Handler) http.Handler {
+	header := config.RequestIDHeader
+	if header == "" {
+		header = DefaultRequestIDHeader
+	}
+	generate := config.GenerateRequestID
+	if generate == nil {
+		generate = rand.Text
+	}
+	message := config.Message
+	if message == "" {
+		message = "http request"
+	}
+	// The access line is logged from here rather than from the handler, so a
+	// caller or stack trace would only point into net/http
+	access := l.clone(l.logger.WithOptions(
+		zap.WithCaller(false),
+		zap.AddStacktrace(zap.LevelEnablerFunc(func(zapcore.Level) bool { return false })),
+	))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			id := r.Header.Get(header)
+			if !validRequestID(id) {
+				id = generate()
+			}
+			w.Header().Set(header, id)
+
+			requestFields := []gsr.LoggerField{
+				Field("request_id", id),
+				Field("method", r.Method),
+				Field("path", r.URL.Path),
+			}
+			requestLogger := l.With(requestFields...)
+			ctx := context.WithValue(r.Context(), requestIDKey{}, id)
+			ctx = ContextWithTraceparent(ctx, r.Header.Get(TraceparentHeader))
+			ctx = NewContext(ctx, requestLogger)
+			r = r.WithContext(ctx)
+
+			rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rec, r)
+
+			if config.Skip != nil && config.Skip(r) {
+				return
+			}
+			level := statusLevel(config.StatusLevels, rec.status)
+			if ce := access.logger.Check(level.toZapLevel(), message); ce != nil {
+				fields := append(requestFields,
+					Field("status", rec.status),
+					Field("bytes", rec.bytes),
+					Field("latency", time.Since(start)),
+					Field("remote_addr", r.RemoteAddr),
+					Field("proto", r.Proto),
+					Field("user_agent", r.UserAgent()),
+				)
+				if r.URL.RawQuery != "" {
+					fields = append(fields, Field("query", r.URL.RawQuery))
+				}
+				if referer := r.Referer(); referer != "" {
+					fields = append(fields, Field("referer", referer))
+				}
+				ce.Write(append(access.contextFields(ctx, fields), accessLineField(start))...)
+			}
+		})
+	}
+}
+
+// RequestIDFromContext returns the request ID stored by HTTPMiddleware, or
+// "" if ctx has none
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// validRequestID reports whether id is a non-empty, reasonably short string
+// of printable ASCII characters, safe to log and echo back
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// statusLevel returns the level for status from levels, keyed by status
+// class, falling back to Warn for 4xx, Error for 5xx and Info otherwise
+func statusLevel(levels map[int]Level, status int) Level {
+	class := status / 100
+	if level, ok := levels[class]; ok {
+		return level
+	}
+	switch class {
+	case 4:
+		return WarnLevel
+	case 5:
+		return ErrorLevel
+	default:
+		return InfoLevel
+	}
+}
+
+// responseRecorder records the status code and body size written by a handler
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+// WriteHeader records the final status code. Informational 1xx responses
+// are passed on without being recorded.
+func (w *responseRecorder) WriteHeader(status int) {
+	if !w.wroteHeader && status >= 200 {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write counts the bytes written to the body
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher if the underlying writer does
+func (w *responseRecorder) Flush() {
+	w.wroteHeader = true
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker if the underlying writer does
+func (w *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("golog: response writer does not support hijacking")
+	}
+	w.status = http.StatusSwitchingProtocols
+	w.wroteHeader = true
+	return h.Hijack()
+}
+
+// Unwrap returns the underlying writer for http.ResponseController
+func (w *responseRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
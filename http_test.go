@@ -0,0 +1,207 @@
+package golog
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestHTTPMiddleware(t *testing.T) {
+	logger, logs := newObservedLogger(TraceLevel)
+	handler := logger.HTTPMiddleware(HTTPConfig{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		FromContext(r.Context()).Info("handling")
+		if RequestIDFromContext(r.Context()) != "req-42" {
+			t.Errorf("Expected the incoming request ID in the context, got %q", RequestIDFromContext(r.Context()))
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("created"))
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/orders?id=1", nil)
+	req.Header.Set("X-Request-ID", "req-42")
+	req.Header.Set("User-Agent", "test-agent")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("X-Request-ID"); got != "req-42" {
+		t.Errorf("Expected the request ID to be echoed, got %q", got)
+	}
+	entries := logs.All()
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+	for _, entry := range entries {
+		fields := entry.ContextMap()
+		if fields["request_id"] != "req-42" || fields["method"] != "POST" || fields["path"] != "/orders" {
+			t.Errorf("Expected request fields on %q, got %v", entry.Message, fields)
+		}
+	}
+
+	access := entries[1]
+	fields := access.ContextMap()
+	if access.Message != "http request" || fromZapLevel(access.Level) != InfoLevel {
+		t.Errorf("Unexpected access line %q at %v", access.Message, fromZapLevel(access.Level))
+	}
+	if fields["status"] != int64(201) || fields["bytes"] != int64(7) || fields["user_agent"] != "test-agent" || fields["remote_addr"] != req.RemoteAddr {
+		t.Errorf("Unexpected access fields %v", fields)
+	}
+	if latency, ok := fields["latency"].(time.Duration); !ok || latency <= 0 {
+		t.Errorf("Expected a positive latency, got %v", fields["latency"])
+	}
+}
+
+func TestHTTPMiddlewareCaller(t *testing.T) {
+	logger, logs := newObservedLogger(InfoLevel, zap.AddCaller(), zap.AddCallerSkip(1))
+	handler := logger.HTTPMiddleware(HTTPConfig{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		FromContext(r.Context()).Info("handling")
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	entries := logs.All()
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+	if caller := entries[0].Caller; !caller.Defined || !strings.HasSuffix(caller.File, "http_test.go") {
+		t.Errorf("Expected the handler's entry to keep its caller, got %v", caller)
+	}
+	if caller := entries[1].Caller; caller.Defined {
+		t.Errorf("Expected the access line to have no caller, got %v", caller)
+	}
+}
+
+func TestHTTPMiddlewareServerError(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "app.log")
+	logger := newFileLogger(t, Config{Level: InfoLevel, OutputPaths: []string{filename}})
+	handler := logger.HTTPMiddleware(HTTPConfig{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	logger.Sync()
+
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(readFile(t, filename)), &entry); err != nil {
+		t.Fatalf("Expected one JSON access line: %v", err)
+	}
+	if entry["level"] != "error" || entry["status"] != float64(500) {
+		t.Errorf("Expected an error access line for the 500, got %v", entry)
+	}
+	if _, ok := entry["stacktrace"]; ok {
+		t.Errorf("Expected no stack trace on the access line, got %v", entry["stacktrace"])
+	}
+	if _, ok := entry["caller"]; ok {
+		t.Errorf("Expected no caller on the access line, got %v", entry["caller"])
+	}
+}
+
+func TestHTTPMiddlewareRequestID(t *testing.T) {
+	logger, logs := newObservedLogger(InfoLevel)
+	handler := logger.HTTPMiddleware(HTTPConfig{
+		RequestIDHeader:   "X-Correlation-ID",
+		GenerateRequestID: func() string { return "generated" },
+	})(http.NotFoundHandler())
+
+	for _, incoming := range []string{"", "has space", strings.Repeat("x", 200)} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-Correlation-ID", incoming)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if got := rec.Header().Get("X-Correlation-ID"); got != "generated" {
+			t.Errorf("Expected a generated ID for %q, got %q", incoming, got)
+		}
+	}
+
+	// the default generator produces distinct IDs
+	handler = logger.HTTPMiddleware(HTTPConfig{})(http.NotFoundHandler())
+	ids := map[string]bool{}
+	for i := 0; i < 3; i++ {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		ids[rec.Header().Get("X-Request-ID")] = true
+	}
+	if len(ids) != 3 || ids[""] {
+		t.Errorf("Expected 3 distinct request IDs, got %v", ids)
+	}
+	if n := logs.Len(); n != 6 {
+		t.Errorf("Expected an access line per request, got %d", n)
+	}
+}
+
+func TestHTTPMiddlewareStatusLevels(t *testing.T) {
+	logger, logs := newObservedLogger(TraceLevel)
+	status := 0
+	handler := logger.HTTPMiddleware(HTTPConfig{
+		StatusLevels: map[int]Level{2: DebugLevel},
+		Skip:         func(r *http.Request) bool { return r.URL.Path == "/healthz" },
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+
+	tests := []struct {
+		status int
+		want   Level
+	}{
+		{http.StatusOK, DebugLevel},
+		{http.StatusFound, InfoLevel},
+		{http.StatusNotFound, WarnLevel},
+		{http.StatusServiceUnavailable, ErrorLevel},
+	}
+	for _, tt := range tests {
+		status = tt.status
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		entries := logs.TakeAll()
+		if len(entries) != 1 || fromZapLevel(entries[0].Level) != tt.want {
+			t.Errorf("Expected one entry at %v for status %d, got %v", tt.want, tt.status, entries)
+		}
+	}
+
+	status = http.StatusOK
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if n := logs.Len(); n != 0 {
+		t.Errorf("Expected skipped requests not to be logged, got %d entries", n)
+	}
+}
+
+func TestHTTPMiddlewareTraceparent(t *testing.T) {
+	t.Cleanup(func() { extractors.Store(nil) })
+	RegisterContextExtractor(TraceparentExtractor)
+	logger, logs := newObservedLogger(InfoLevel)
+	handler := logger.HTTPMiddleware(HTTPConfig{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	entries := logs.All()
+	if len(entries) != 1 || entries[0].ContextMap()["trace_id"] != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("Expected the access line to carry the trace ID, got %v", entries)
+	}
+}
+
+func TestResponseRecorder(t *testing.T) {
+	rec := httptest.NewRecorder()
+	w := &responseRecorder{ResponseWriter: rec, status: http.StatusOK}
+
+	w.WriteHeader(http.StatusAccepted)
+	w.WriteHeader(http.StatusInternalServerError)
+	w.Write([]byte("hello"))
+	w.Flush()
+
+	if w.status != http.StatusAccepted || w.bytes != 5 {
+		t.Errorf("Expected status 202 and 5 bytes, got %d and %d", w.status, w.bytes)
+	}
+	if !rec.Flushed {
+		t.Error("Expected Flush to reach the underlying writer")
+	}
+	if err := http.NewResponseController(w).Flush(); err != nil {
+		t.Errorf("Expected ResponseController to find the underlying writer, got %v", err)
+	}
+}
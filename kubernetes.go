@@ -0,0 +1,100 @@
+package golog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// defaultPodInfoDir is where KubernetesConfig looks for downward API files
+// when PodInfoDir is empty
+const defaultPodInfoDir = "/etc/podinfo"
+
+// serviceAccountNamespaceFile holds the pod's namespace in every pod that
+// mounts a service account token
+const serviceAccountNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+// KubernetesConfig adds the pod name, namespace, node and container to every
+// entry, as a "k8s" object. Each value is read from an environment variable
+// set through the downward API, or else from a file of the same name in
+// lower case in a downward API volume mounted at PodInfoDir:
+//
+//	pod        POD_NAME or pod_name
+//	namespace  POD_NAMESPACE or pod_namespace, then the service account namespace
+//	node       NODE_NAME or node_name
+//	container  CONTAINER_NAME or container_name
+//
+// Values that can't be found are omitted. Example pod spec:
+//
+//	env:
+//	- name: POD_NAME
+//	  valueFrom: {fieldRef: {fieldPath: metadata.name}}
+//	- name: POD_NAMESPACE
+//	  valueFrom: {fieldRef: {fieldPath: metadata.namespace}}
+//	- name: NODE_NAME
+//	  valueFrom: {fieldRef: {fieldPath: spec.nodeName}}
+//	- name: CONTAINER_NAME
+//	  value: app
+type KubernetesConfig struct {
+	// PodInfoDir is the mount path of a downward API volume. Default: /etc/podinfo
+	PodInfoDir string `json:"podInfoDir" yaml:"podInfoDir"`
+}
+
+// podInfo is the "k8s" object added by KubernetesConfig
+type podInfo struct {
+	pod, namespace, node, container string
+}
+
+// MarshalLogObject implements zapcore.ObjectMarshaler, leaving out empty values
+func (p podInfo) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	add := func(key, value string) {
+		if value != "" {
+			enc.AddString(key, value)
+		}
+	}
+	add("pod", p.pod)
+	add("namespace", p.namespace)
+	add("node", p.node)
+	add("container", p.container)
+	return nil
+}
+
+// field returns the "k8s" field, or false if none of the values were found
+func (c KubernetesConfig) field() (zapcore.Field, bool) {
+	dir := c.PodInfoDir
+	if dir == "" {
+		dir = defaultPodInfoDir
+	}
+	lookup := func(name string) string {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+		return readPodInfo(filepath.Join(dir, strings.ToLower(name)))
+	}
+
+	info := podInfo{
+		pod:       lookup("POD_NAME"),
+		namespace: lookup("POD_NAMESPACE"),
+		node:      lookup("NODE_NAME"),
+		container: lookup("CONTAINER_NAME"),
+	}
+	if info.namespace == "" {
+		info.namespace = readPodInfo(serviceAccountNamespaceFile)
+	}
+	if info == (podInfo{}) {
+		return zapcore.Field{}, false
+	}
+	return zap.Object("k8s", info), true
+}
+
+// readPodInfo returns the trimmed contents of filename, or "" if it can't be read
+func readPodInfo(filename string) string {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
@@ -0,0 +1,71 @@
+package golog
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestKubernetes(t *testing.T) {
+	podInfo := t.TempDir()
+	if err := os.WriteFile(filepath.Join(podInfo, "node_name"), []byte("node-7\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(podInfo, "pod_name"), []byte("ignored"), 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	t.Setenv("POD_NAME", "api-5d8f-x2x")
+	t.Setenv("POD_NAMESPACE", "payments")
+	t.Setenv("NODE_NAME", "")
+	t.Setenv("CONTAINER_NAME", "app")
+
+	filename := filepath.Join(t.TempDir(), "app.log")
+	config := DefaultConfig()
+	config.OutputPaths = []string{filename}
+	config.Kubernetes = &KubernetesConfig{PodInfoDir: podInfo}
+	logger := newFileLogger(t, config)
+
+	logger.Info("hello")
+	logger.Sync()
+
+	var entry struct {
+		K8s map[string]string `json:"k8s"`
+	}
+	if err := json.Unmarshal([]byte(readFile(t, filename)), &entry); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	want := map[string]string{"pod": "api-5d8f-x2x", "namespace": "payments", "node": "node-7", "container": "app"}
+	for key, value := range want {
+		if entry.K8s[key] != value {
+			t.Errorf("Expected k8s.%s=%q, got %q", key, value, entry.K8s[key])
+		}
+	}
+}
+
+func TestKubernetesOutsideCluster(t *testing.T) {
+	if _, err := os.Stat(serviceAccountNamespaceFile); err == nil {
+		t.Skip("running inside a pod")
+	}
+	for _, name := range []string{"POD_NAME", "POD_NAMESPACE", "NODE_NAME", "CONTAINER_NAME"} {
+		t.Setenv(name, "")
+	}
+
+	if f, ok := (KubernetesConfig{PodInfoDir: t.TempDir()}).field(); ok {
+		t.Errorf("Expected no k8s field outside a cluster, got %v", f)
+	}
+}
+
+func TestKubernetesLoadConfig(t *testing.T) {
+	path := writeConfigFile(t, "log.yaml", `
+kubernetes:
+  podInfoDir: /etc/downward
+`)
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if config.Kubernetes == nil || config.Kubernetes.PodInfoDir != "/etc/downward" {
+		t.Errorf("Unexpected Kubernetes config %+v", config.Kubernetes)
+	}
+}
@@ -0,0 +1,181 @@
+package golog
+
+import (
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// zap has no room between its own levels, so golog's extra levels are
+// represented by zapcore.Level values outside zap's range. golog always
+// compares levels after converting them back with fromZapLevel, so their
+// order is preserved. Cores built outside golog never see these values:
+// NewLoggerWithZap replaces them with the nearest zap level.
+const (
+	zapTraceLevel     = zapcore.DebugLevel - 1
+	zapNoticeLevel    = zapcore.Level(10)
+	zapCriticalLevel  = zapcore.Level(11)
+	zapAlertLevel     = zapcore.Level(12)
+	zapEmergencyLevel = zapcore.Level(13)
+)
+
+// ParseLevel parses a level name, ignoring case and surrounding spaces.
+// It accepts the names returned by Level.String as well as the common
+// syslog aliases "warning", "err", "crit" and "emerg". An empty string
+// parses as InfoLevel.
+//
+// Example:
+//
+//	level, err := golog.ParseLevel(os.Getenv("LOG_LEVEL"))
+func ParseLevel(text string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(text)) {
+	case "trace":
+		return TraceLevel, nil
+	case "debug":
+		return DebugLevel, nil
+	case "info", "":
+		return InfoLevel, nil
+	case "notice":
+		return NoticeLevel, nil
+	case "warn", "warning":
+		return WarnLevel, nil
+	case "error", "err":
+		return ErrorLevel, nil
+	case "critical", "crit":
+		return CriticalLevel, nil
+	case "alert":
+		return AlertLevel, nil
+	case "emergency", "emerg":
+		return EmergencyLevel, nil
+	case "fatal":
+		return FatalLevel, nil
+	case "panic":
+		return PanicLevel, nil
+	default:
+		return InfoLevel, fmt.Errorf("golog: unknown level %q", text)
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler, so levels are written by
+// name in JSON, YAML and other text-based formats
+func (l Level) MarshalText() ([]byte, error) {
+	if l < TraceLevel || l > PanicLevel {
+		return nil, fmt.Errorf("golog: can't marshal unknown level %d", l)
+	}
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler using ParseLevel, so
+// levels can be decoded from JSON, YAML and other text-based formats
+func (l *Level) UnmarshalText(text []byte) error {
+	level, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*l = level
+	return nil
+}
+
+// Set implements flag.Value, so a Level can be bound to a command-line flag:
+//
+//	level := golog.InfoLevel
+//	flag.Var(&level, "log-level", "minimum log level")
+func (l *Level) Set(text string) error {
+	return l.UnmarshalText([]byte(text))
+}
+
+// Get implements flag.Getter
+func (l *Level) Get() any {
+	return *l
+}
+
+// Enabled implements zapcore.LevelEnabler using golog's level order, so a
+// Level can be passed to zap options such as zap.AddStacktrace and
+// zap.IncreaseLevel
+func (l Level) Enabled(lvl zapcore.Level) bool {
+	return fromZapLevel(lvl) >= l
+}
+
+// SyslogSeverity returns the RFC 5424 severity code for the level, from 0
+// (Emergency) to 7 (Debug). Trace maps to Debug; Fatal and Panic map to Critical.
+func (l Level) SyslogSeverity() int {
+	switch {
+	case l <= DebugLevel:
+		return 7
+	case l == InfoLevel:
+		return 6
+	case l == NoticeLevel:
+		return 5
+	case l == WarnLevel:
+		return 4
+	case l == ErrorLevel:
+		return 3
+	case l == AlertLevel:
+		return 1
+	case l == EmergencyLevel:
+		return 0
+	default:
+		return 2
+	}
+}
+
+// LowercaseLevelEncoder is a zapcore.LevelEncoder that writes golog level
+// names in lower case, e.g. "notice". Unlike zap's encoders it knows golog's
+// extra levels; zap's DPanic level is written as "dpanic".
+func LowercaseLevelEncoder(l zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+	enc.AppendString(levelName(l))
+}
+
+// CapitalLevelEncoder is a zapcore.LevelEncoder that writes golog level
+// names in upper case, e.g. "NOTICE"
+func CapitalLevelEncoder(l zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+	enc.AppendString(strings.ToUpper(levelName(l)))
+}
+
+// LowercaseColorLevelEncoder is like LowercaseLevelEncoder, but adds ANSI
+// colors for console output
+func LowercaseColorLevelEncoder(l zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+	enc.AppendString(levelColor(l) + levelName(l) + colorReset)
+}
+
+// CapitalColorLevelEncoder is like CapitalLevelEncoder, but adds ANSI
+// colors for console output
+func CapitalColorLevelEncoder(l zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+	enc.AppendString(levelColor(l) + strings.ToUpper(levelName(l)) + colorReset)
+}
+
+// levelName returns the golog name for a zap level
+func levelName(l zapcore.Level) string {
+	if l == zapcore.DPanicLevel {
+		return l.String()
+	}
+	return fromZapLevel(l).String()
+}
+
+// ANSI escape sequences used by the color level encoders
+const (
+	colorReset   = "\x1b[0m"
+	colorRed     = "\x1b[31m"
+	colorYellow  = "\x1b[33m"
+	colorBlue    = "\x1b[34m"
+	colorMagenta = "\x1b[35m"
+	colorCyan    = "\x1b[36m"
+)
+
+// levelColor returns the ANSI color for a zap level, matching zap's colors
+// for the levels zap knows about
+func levelColor(l zapcore.Level) string {
+	switch level := fromZapLevel(l); {
+	case level <= DebugLevel:
+		return colorMagenta
+	case level == InfoLevel:
+		return colorBlue
+	case level == NoticeLevel:
+		return colorCyan
+	case level == WarnLevel:
+		return colorYellow
+	default:
+		return colorRed
+	}
+}
@@ -0,0 +1,328 @@
+package golog
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// allLevels enables every level. Cores built by golog use it and leave
+// filtering to the levelCore wrapped around them, so that named loggers can
+// be more verbose than the global level.
+var allLevels = zap.LevelEnablerFunc(func(zapcore.Level) bool { return true })
+
+// levelSet holds a logger's global level and the per-name overrides set with
+// SetNamedLevel. It is shared by a logger and all of its children.
+//
+// Levels are compared in golog's order rather than by their zapcore.Level
+// values, which is what lets golog's extra levels sit between zap's.
+type levelSet struct {
+	global atomic.Int32
+
+	mu        sync.RWMutex
+	overrides []levelOverride
+	// hasOverrides and overrideMin let Enabled and enabledFor avoid the lock
+	hasOverrides atomic.Bool
+	overrideMin  atomic.Int32
+}
+
+// levelOverride sets the level for logger names matching pattern
+type levelOverride struct {
+	pattern string
+	level   Level
+}
+
+// newLevelSet creates a levelSet with the given global level and no overrides
+func newLevelSet(level Level) *levelSet {
+	s := &levelSet{}
+	s.global.Store(int32(level))
+	return s
+}
+
+// globalLevel returns the global level
+func (s *levelSet) globalLevel() Level {
+	return Level(s.global.Load())
+}
+
+// setGlobal changes the global level
+func (s *levelSet) setGlobal(level Level) {
+	s.global.Store(int32(level))
+}
+
+// Enabled reports whether lvl is enabled for at least one logger name
+func (s *levelSet) Enabled(lvl zapcore.Level) bool {
+	level := fromZapLevel(lvl)
+	if level >= s.globalLevel() {
+		return true
+	}
+	return s.hasOverrides.Load() && level >= Level(s.overrideMin.Load())
+}
+
+// levelFor returns the effective level for a logger name: the level of the
+// most specific matching override, or the global level
+func (s *levelSet) levelFor(name string) Level {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// overrides are sorted most specific first
+	for _, o := range s.overrides {
+		if matchName(o.pattern, name) {
+			return o.level
+		}
+	}
+	return s.globalLevel()
+}
+
+// enabledFor reports whether lvl is enabled for the named logger
+func (s *levelSet) enabledFor(name string, lvl zapcore.Level) bool {
+	if !s.hasOverrides.Load() {
+		return fromZapLevel(lvl) >= s.globalLevel()
+	}
+	return fromZapLevel(lvl) >= s.levelFor(name)
+}
+
+// set adds or replaces the override for pattern
+func (s *levelSet) set(pattern string, level Level) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.remove(pattern)
+	s.overrides = append(s.overrides, levelOverride{pattern: pattern, level: level})
+	s.updateLocked()
+}
+
+// clear removes the override for pattern
+func (s *levelSet) clear(pattern string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.remove(pattern)
+	s.updateLocked()
+}
+
+// replace replaces all overrides
+func (s *levelSet) replace(overrides map[string]Level) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.overrides = s.overrides[:0]
+	for pattern, level := range overrides {
+		s.overrides = append(s.overrides, levelOverride{pattern: pattern, level: level})
+	}
+	s.updateLocked()
+}
+
+// remove deletes the override for pattern; the caller must hold s.mu
+func (s *levelSet) remove(pattern string) {
+	for i, o := range s.overrides {
+		if o.pattern == pattern {
+			s.overrides = append(s.overrides[:i], s.overrides[i+1:]...)
+			return
+		}
+	}
+}
+
+// updateLocked re-sorts overrides and recomputes overrideMin; the caller must hold s.mu
+func (s *levelSet) updateLocked() {
+	sort.SliceStable(s.overrides, func(i, j int) bool {
+		return specificity(s.overrides[i].pattern) > specificity(s.overrides[j].pattern)
+	})
+
+	min := PanicLevel
+	for _, o := range s.overrides {
+		if o.level < min {
+			min = o.level
+		}
+	}
+	s.overrideMin.Store(int32(min))
+	s.hasOverrides.Store(len(s.overrides) > 0)
+}
+
+// matchName reports whether a logger name matches an override pattern.
+// "billing.cron" matches only that name, "billing.*" matches "billing" and
+// every name below it, and "*" matches every name.
+func matchName(pattern, name string) bool {
+	if pattern == "*" {
+		return true
+	}
+	if prefix, ok := strings.CutSuffix(pattern, ".*"); ok {
+		return name == prefix || strings.HasPrefix(name, prefix+".")
+	}
+	return name == pattern
+}
+
+// specificity ranks patterns so that exact names beat wildcards and longer
+// prefixes beat shorter ones
+func specificity(pattern string) int {
+	if pattern == "*" {
+		return 0
+	}
+	if prefix, ok := strings.CutSuffix(pattern, ".*"); ok {
+		return 2 * len(prefix)
+	}
+	return 2*len(pattern) + 1
+}
+
+// parseLevelSpec parses a comma-separated list of pattern=level pairs,
+// e.g. "billing.*=debug,db=warn"
+func parseLevelSpec(spec string) (map[string]Level, error) {
+	overrides := make(map[string]Level)
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		pattern, text, ok := strings.Cut(item, "=")
+		pattern = strings.TrimSpace(pattern)
+		if !ok || pattern == "" {
+			return nil, fmt.Errorf("golog: invalid level override %q, want pattern=level", item)
+		}
+		level, err := ParseLevel(strings.TrimSpace(text))
+		if err != nil {
+			return nil, err
+		}
+		overrides[pattern] = level
+	}
+	return overrides, nil
+}
+
+// levelCore filters entries below an adjustable level before passing them
+// to the wrapped core. It also lets SetLevel work on loggers whose core was
+// built elsewhere, such as those passed to NewLoggerWithZap.
+type levelCore struct {
+	zapcore.Core
+	levels *levelSet
+}
+
+// Enabled reports whether both the level and the wrapped core allow lvl
+func (c *levelCore) Enabled(lvl zapcore.Level) bool {
+	return c.levels.Enabled(lvl) && c.Core.Enabled(lvl)
+}
+
+// With adds structured context to the wrapped core
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{Core: c.Core.With(fields), levels: c.levels}
+}
+
+// Check drops entries below the effective level for the entry's logger name
+// and defers to the wrapped core otherwise
+func (c *levelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.levels.enabledFor(ent.LoggerName, ent.Level) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
+
+// ioCore is zapcore's io core with golog's notion of severity: like zap's,
+// it syncs its output after entries above ErrorLevel, since the program may
+// be about to crash, but it compares levels in golog's order so that Notice
+// entries don't sync every line.
+type ioCore struct {
+	zapcore.LevelEnabler
+	enc zapcore.Encoder
+	out zapcore.WriteSyncer
+}
+
+// newIOCore creates a core that writes entries enabled by enab to out
+func newIOCore(enc zapcore.Encoder, out zapcore.WriteSyncer, enab zapcore.LevelEnabler) zapcore.Core {
+	return &ioCore{LevelEnabler: enab, enc: enc, out: out}
+}
+
+// Level returns the minimum enabled level
+func (c *ioCore) Level() zapcore.Level {
+	return zapcore.LevelOf(c.LevelEnabler)
+}
+
+// With adds structured context to a copy of the core
+func (c *ioCore) With(fields []zapcore.Field) zapcore.Core {
+	enc := c.enc.Clone()
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	return &ioCore{LevelEnabler: c.LevelEnabler, enc: enc, out: c.out}
+}
+
+// Check adds the core to ce if the entry's level is enabled
+func (c *ioCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write encodes the entry and writes it to the output
+func (c *ioCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	_, err = c.out.Write(buf.Bytes())
+	buf.Free()
+	if err != nil {
+		return err
+	}
+	if fromZapLevel(ent.Level) > ErrorLevel {
+		// Ignore Sync errors, as zap does
+		_ = c.Sync()
+	}
+	return nil
+}
+
+// Sync flushes the output
+func (c *ioCore) Sync() error {
+	return c.out.Sync()
+}
+
+// zapLevelCore passes entries to a core built outside golog with golog's
+// extra levels replaced by the nearest zap level: Trace by Debug, Notice by
+// Info and Critical, Alert and Emergency by Error. Such cores don't know the
+// extra values and would otherwise write them as "Level(10)" and treat them
+// as more severe than Error.
+type zapLevelCore struct {
+	zapcore.Core
+}
+
+// Enabled reports whether the wrapped core accepts lvl's zap equivalent
+func (c *zapLevelCore) Enabled(lvl zapcore.Level) bool {
+	return c.Core.Enabled(nativeZapLevel(lvl))
+}
+
+// With adds structured context to the wrapped core
+func (c *zapLevelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &zapLevelCore{Core: c.Core.With(fields)}
+}
+
+// Check passes the entry to the wrapped core with its zap equivalent level.
+// zap.Logger checks its core with a nil CheckedEntry, so the entry created
+// by the wrapped core, and the stack trace decision made from it, carry the
+// zap level too.
+func (c *zapLevelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	ent.Level = nativeZapLevel(ent.Level)
+	return c.Core.Check(ent, ce)
+}
+
+// Write writes the entry to the wrapped core with its zap equivalent level
+func (c *zapLevelCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	ent.Level = nativeZapLevel(ent.Level)
+	return c.Core.Write(ent, fields)
+}
+
+// nativeZapLevel returns the zap level closest to lvl, which is lvl itself
+// unless it is one of golog's extra levels
+func nativeZapLevel(lvl zapcore.Level) zapcore.Level {
+	switch lvl {
+	case zapTraceLevel:
+		return zapcore.DebugLevel
+	case zapNoticeLevel:
+		return zapcore.InfoLevel
+	case zapCriticalLevel, zapAlertLevel, zapEmergencyLevel:
+		return zapcore.ErrorLevel
+	default:
+		return lvl
+	}
+}
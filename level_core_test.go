@@ -0,0 +1,206 @@
+package golog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/muleiwu/gsr"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// newObservedLogger returns a logger at level that records every entry,
+// built with opts such as zap.AddCaller
+func newObservedLogger(level Level, opts ...zap.Option) (*Logger, *observer.ObservedLogs) {
+	core, logs := observer.New(zapTraceLevel)
+	return newCoreLogger(core, newLevelSet(level), opts...), logs
+}
+
+func TestNamed(t *testing.T) {
+	logger, logs := newObservedLogger(InfoLevel)
+
+	logger.Named("billing").Named("cron").Info("tick")
+
+	entries := logs.All()
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 entry, got %d", len(entries))
+	}
+	if got := entries[0].LoggerName; got != "billing.cron" {
+		t.Errorf("Expected logger name 'billing.cron', got %q", got)
+	}
+}
+
+func TestSetNamedLevel(t *testing.T) {
+	logger, logs := newObservedLogger(InfoLevel)
+	billing := logger.Named("billing")
+	cron := billing.Named("cron")
+	db := logger.Named("db")
+
+	logger.SetNamedLevel("billing.*", DebugLevel)
+	logger.SetNamedLevel("billing.cron", ErrorLevel)
+
+	billing.Debug("billing debug")
+	cron.Warn("cron warn")
+	cron.Error("cron error")
+	db.Debug("db debug")
+	db.Info("db info")
+
+	var messages []string
+	for _, e := range logs.All() {
+		messages = append(messages, e.Message)
+	}
+	want := []string{"billing debug", "cron error", "db info"}
+	if len(messages) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, messages)
+	}
+	for i := range want {
+		if messages[i] != want[i] {
+			t.Errorf("Expected %v, got %v", want, messages)
+			break
+		}
+	}
+
+	if got := billing.Level(); got != DebugLevel {
+		t.Errorf("Expected billing level %v, got %v", DebugLevel, got)
+	}
+	if got := db.Level(); got != InfoLevel {
+		t.Errorf("Expected db level %v, got %v", InfoLevel, got)
+	}
+
+	logger.ClearNamedLevel("billing.*")
+	if got := billing.Level(); got != InfoLevel {
+		t.Errorf("Expected billing level %v after clear, got %v", InfoLevel, got)
+	}
+}
+
+func TestSetNamedLevels(t *testing.T) {
+	logger, _ := newObservedLogger(InfoLevel)
+
+	if err := logger.SetNamedLevels("billing.*=debug, db=warn"); err != nil {
+		t.Fatalf("SetNamedLevels failed: %v", err)
+	}
+	if got := logger.Named("billing").Named("api").Level(); got != DebugLevel {
+		t.Errorf("Expected billing.api level %v, got %v", DebugLevel, got)
+	}
+	if got := logger.Named("db").Level(); got != WarnLevel {
+		t.Errorf("Expected db level %v, got %v", WarnLevel, got)
+	}
+
+	for _, spec := range []string{"billing", "=debug", "billing=loud"} {
+		if err := logger.SetNamedLevels(spec); err == nil {
+			t.Errorf("Expected error for spec %q", spec)
+		}
+	}
+}
+
+func TestMatchName(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"*", "anything", true},
+		{"*", "", true},
+		{"billing.*", "billing", true},
+		{"billing.*", "billing.cron", true},
+		{"billing.*", "billingx", false},
+		{"billing.cron", "billing.cron", true},
+		{"billing.cron", "billing.cron.job", false},
+	}
+
+	for _, tt := range tests {
+		if got := matchName(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("matchName(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestConfigNamedLevels(t *testing.T) {
+	logger, err := NewLoggerWithConfig(Config{
+		Level:       WarnLevel,
+		Encoding:    "json",
+		OutputPaths: []string{"stdout"},
+		NamedLevels: map[string]Level{"billing.*": DebugLevel},
+	})
+	if err != nil {
+		t.Fatalf("NewLoggerWithConfig failed: %v", err)
+	}
+	defer logger.Sync()
+
+	if !logger.Named("billing").GetZapLogger().Core().Enabled(zapcore.DebugLevel) {
+		t.Error("Expected debug to be enabled for billing")
+	}
+	if got := logger.Named("api").Level(); got != WarnLevel {
+		t.Errorf("Expected api level %v, got %v", WarnLevel, got)
+	}
+}
+
+// syncCounter is a WriteSyncer that counts calls to Sync
+type syncCounter struct {
+	bytes.Buffer
+	syncs int
+}
+
+func (s *syncCounter) Sync() error {
+	s.syncs++
+	return nil
+}
+
+func TestIOCoreSync(t *testing.T) {
+	out := &syncCounter{}
+	core := newIOCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), out, allLevels)
+
+	tests := []struct {
+		level Level
+		sync  bool
+	}{
+		{InfoLevel, false},
+		{NoticeLevel, false},
+		{ErrorLevel, false},
+		{CriticalLevel, true},
+		{EmergencyLevel, true},
+	}
+	for _, tt := range tests {
+		out.syncs = 0
+		if err := core.Write(zapcore.Entry{Level: tt.level.toZapLevel(), Message: "m"}, nil); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+		if got := out.syncs > 0; got != tt.sync {
+			t.Errorf("Expected sync %v after a %v entry, got %v", tt.sync, tt.level, got)
+		}
+	}
+}
+
+func TestNewLoggerWithZapExtraLevels(t *testing.T) {
+	var buf bytes.Buffer
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(&buf), zapcore.DebugLevel)
+	logger := NewLoggerWithZap(zap.New(core, zap.AddStacktrace(zapcore.ErrorLevel)))
+
+	tests := []struct {
+		log   func(string, ...gsr.LoggerField)
+		level string
+		stack bool
+	}{
+		{logger.Notice, "info", false},
+		{logger.Critical, "error", true},
+		{logger.Alert, "error", true},
+		{logger.Emergency, "error", true},
+	}
+	for _, tt := range tests {
+		buf.Reset()
+		tt.log("m")
+		got := buf.String()
+		if !strings.Contains(got, `"level":"`+tt.level+`"`) || strings.Contains(got, `"stacktrace"`) != tt.stack {
+			t.Errorf("Expected level %q with stack %v, got %s", tt.level, tt.stack, got)
+		}
+	}
+
+	buf.Reset()
+	logger.SetLevel(TraceLevel)
+	logger.Trace("m")
+	if got := buf.String(); !strings.Contains(got, `"level":"debug"`) {
+		t.Errorf("Expected Trace to be written as debug, got %s", got)
+	}
+}
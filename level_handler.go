@@ -0,0 +1,157 @@
+package golog
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxLevelBodySize limits how much of a request body LevelHandler reads
+const maxLevelBodySize = 1024
+
+// LevelHandler is an http.Handler that reports and changes a logger's level
+// at runtime. Mount it on an internal admin mux:
+//
+//	mux.Handle("/debug/log/level", golog.NewLevelHandler(logger))
+//
+// GET returns the current global level, e.g. "info". Overrides set with
+// SetNamedLevel are neither reported nor changed.
+// PUT and POST set the level from the "level" query or form parameter, or
+// from a plain text body. An optional "ttl" parameter (e.g. "15m") reverts
+// the change automatically once it elapses:
+//
+//	curl -X PUT 'localhost:8080/debug/log/level?level=debug&ttl=15m'
+//	curl -X PUT -d debug localhost:8080/debug/log/level
+type LevelHandler struct {
+	logger *Logger
+
+	mu       sync.Mutex
+	revert   *time.Timer
+	revertTo Level
+}
+
+// NewLevelHandler creates a LevelHandler for logger. Because children share
+// their parent's level, changes apply to every logger derived from it.
+func NewLevelHandler(logger *Logger) *LevelHandler {
+	return &LevelHandler{logger: logger}
+}
+
+// ServeHTTP implements http.Handler
+func (h *LevelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.writeLevel(w)
+	case http.MethodPut, http.MethodPost:
+		h.serveSet(w, r)
+	default:
+		w.Header().Set("Allow", "GET, PUT, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// serveSet handles a request to change the level
+func (h *LevelHandler) serveSet(w http.ResponseWriter, r *http.Request) {
+	text, ttlText, err := levelParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if text == "" {
+		http.Error(w, "missing level", http.StatusBadRequest)
+		return
+	}
+
+	level, err := ParseLevel(text)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var ttl time.Duration
+	if ttlText != "" {
+		ttl, err = time.ParseDuration(ttlText)
+		if err != nil || ttl <= 0 {
+			http.Error(w, fmt.Sprintf("invalid ttl %q", ttlText), http.StatusBadRequest)
+			return
+		}
+	}
+
+	h.SetLevel(level, ttl)
+	h.writeLevel(w)
+}
+
+// SetLevel changes the logger's global level. If ttl is positive the level reverts
+// to the one in effect before the first pending change once ttl elapses;
+// a later call without ttl cancels any pending revert.
+func (h *LevelHandler) SetLevel(level Level, ttl time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.revert != nil {
+		h.revert.Stop()
+		h.revert = nil
+	} else {
+		h.revertTo = h.logger.levels.globalLevel()
+	}
+
+	h.logger.SetLevel(level)
+
+	if ttl > 0 {
+		var timer *time.Timer
+		timer = time.AfterFunc(ttl, func() {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+
+			// A newer change may have replaced this timer
+			if h.revert != timer {
+				return
+			}
+			h.revert = nil
+			h.logger.SetLevel(h.revertTo)
+		})
+		h.revert = timer
+	}
+}
+
+// levelParams returns the "level" and "ttl" parameters of r. The level is
+// taken from the query string, then from a form body, and otherwise from the
+// plain text body. The body is read before any form parsing, because
+// "curl -d debug" sends a plain level as application/x-www-form-urlencoded.
+func levelParams(r *http.Request) (level, ttl string, err error) {
+	query := r.URL.Query()
+	level, ttl = query.Get("level"), query.Get("ttl")
+	if level != "" {
+		return level, ttl, nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		return r.FormValue("level"), r.FormValue("ttl"), nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxLevelBodySize))
+	if err != nil {
+		return "", "", err
+	}
+	text := strings.TrimSpace(string(body))
+	if mediaType == "application/x-www-form-urlencoded" {
+		if form, err := url.ParseQuery(text); err == nil && form.Has("level") {
+			if ttl == "" {
+				ttl = form.Get("ttl")
+			}
+			return form.Get("level"), ttl, nil
+		}
+	}
+	return text, ttl, nil
+}
+
+// writeLevel writes the current global level as plain text
+func (h *LevelHandler) writeLevel(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintln(w, h.logger.levels.globalLevel().String())
+}
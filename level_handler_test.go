@@ -0,0 +1,151 @@
+package golog
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func serveLevel(t *testing.T, h http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestLevelHandlerGet(t *testing.T) {
+	logger := NewLogger()
+	logger.SetLevel(WarnLevel)
+
+	rec := serveLevel(t, NewLevelHandler(logger), http.MethodGet, "/level", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	if got := strings.TrimSpace(rec.Body.String()); got != "warn" {
+		t.Errorf("Expected body 'warn', got %q", got)
+	}
+}
+
+func TestLevelHandlerSet(t *testing.T) {
+	logger := NewLogger()
+	child := logger.With(Field("component", "child"))
+	h := NewLevelHandler(logger)
+
+	rec := serveLevel(t, h, http.MethodPut, "/level?level=error", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body)
+	}
+	if got := child.Level(); got != ErrorLevel {
+		t.Errorf("Expected child level %v, got %v", ErrorLevel, got)
+	}
+
+	rec = serveLevel(t, h, http.MethodPost, "/level", "INFO\n")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body)
+	}
+	if got := logger.Level(); got != InfoLevel {
+		t.Errorf("Expected level %v, got %v", InfoLevel, got)
+	}
+}
+
+func TestLevelHandlerFormBody(t *testing.T) {
+	logger := NewLogger()
+	h := NewLevelHandler(logger)
+
+	tests := []struct {
+		target string
+		body   string
+		want   Level
+	}{
+		// curl -d sends a plain body as a form
+		{"/level", "debug", DebugLevel},
+		{"/level", "level=warn", WarnLevel},
+		{"/level?ttl=1h", "error", ErrorLevel},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPut, tt.target, strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s with body %q: expected status 200, got %d: %s", tt.target, tt.body, rec.Code, rec.Body)
+		}
+		if got := logger.Level(); got != tt.want {
+			t.Errorf("%s with body %q: expected level %v, got %v", tt.target, tt.body, tt.want, got)
+		}
+	}
+	h.SetLevel(InfoLevel, 0)
+}
+
+func TestLevelHandlerErrors(t *testing.T) {
+	h := NewLevelHandler(NewLogger())
+
+	tests := []struct {
+		method string
+		target string
+		body   string
+		code   int
+	}{
+		{http.MethodPut, "/level?level=verbose", "", http.StatusBadRequest},
+		{http.MethodPut, "/level", "", http.StatusBadRequest},
+		{http.MethodPut, "/level?level=debug&ttl=soon", "", http.StatusBadRequest},
+		{http.MethodDelete, "/level", "", http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		rec := serveLevel(t, h, tt.method, tt.target, tt.body)
+		if rec.Code != tt.code {
+			t.Errorf("%s %s: expected status %d, got %d", tt.method, tt.target, tt.code, rec.Code)
+		}
+	}
+}
+
+func TestLevelHandlerTTL(t *testing.T) {
+	logger := NewLogger()
+	logger.SetLevel(InfoLevel)
+	h := NewLevelHandler(logger)
+
+	rec := serveLevel(t, h, http.MethodPut, "/level?level=debug&ttl=20ms", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body)
+	}
+	if got := logger.Level(); got != DebugLevel {
+		t.Fatalf("Expected level %v, got %v", DebugLevel, got)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for logger.Level() != InfoLevel {
+		if time.Now().After(deadline) {
+			t.Fatal("Level was not reverted after ttl")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestLevelHandlerNamedOverride(t *testing.T) {
+	logger := NewLogger()
+	logger.SetLevel(InfoLevel)
+	logger.SetNamedLevel("*", WarnLevel)
+	h := NewLevelHandler(logger)
+
+	rec := serveLevel(t, h, http.MethodPut, "/level?level=debug&ttl=20ms", "")
+	if got := strings.TrimSpace(rec.Body.String()); got != "debug" {
+		t.Errorf("Expected the global level 'debug', got %q", got)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for logger.levels.globalLevel() != InfoLevel {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the global level to revert to %v, got %v", InfoLevel, logger.levels.globalLevel())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	logger.ClearNamedLevel("*")
+	if got := logger.Level(); got != InfoLevel {
+		t.Errorf("Expected level %v after clearing the override, got %v", InfoLevel, got)
+	}
+}
@@ -0,0 +1,196 @@
+package golog
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/muleiwu/gsr"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		text string
+		want Level
+	}{
+		{"trace", TraceLevel},
+		{"debug", DebugLevel},
+		{"INFO", InfoLevel},
+		{"Notice", NoticeLevel},
+		{"", InfoLevel},
+		{"warn", WarnLevel},
+		{"WARNING", WarnLevel},
+		{" error ", ErrorLevel},
+		{"err", ErrorLevel},
+		{"crit", CriticalLevel},
+		{"alert", AlertLevel},
+		{"EMERG", EmergencyLevel},
+		{"Fatal", FatalLevel},
+		{"panic", PanicLevel},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseLevel(tt.text)
+		if err != nil {
+			t.Errorf("ParseLevel(%q) failed: %v", tt.text, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseLevel(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Error("Expected error for unknown level")
+	}
+}
+
+func TestLevelJSON(t *testing.T) {
+	var decoded struct {
+		Level  Level            `json:"level"`
+		Levels map[string]Level `json:"levels"`
+	}
+	if err := json.Unmarshal([]byte(`{"level":"WARNING","levels":{"db":"debug"}}`), &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if decoded.Level != WarnLevel || decoded.Levels["db"] != DebugLevel {
+		t.Errorf("Unexpected decoded levels: %+v", decoded)
+	}
+
+	data, err := json.Marshal(decoded)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if string(data) != `{"level":"warn","levels":{"db":"debug"}}` {
+		t.Errorf("Unexpected JSON %s", data)
+	}
+
+	if err := json.Unmarshal([]byte(`{"level":"loud"}`), &decoded); err == nil {
+		t.Error("Expected error for unknown level")
+	}
+	if _, err := json.Marshal(Level(42)); err == nil {
+		t.Error("Expected error marshaling unknown level")
+	}
+}
+
+func TestLevelFlag(t *testing.T) {
+	level := InfoLevel
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Var(&level, "log-level", "minimum log level")
+
+	if err := fs.Parse([]string{"-log-level", "error"}); err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if level != ErrorLevel {
+		t.Errorf("Expected %v, got %v", ErrorLevel, level)
+	}
+	if got := fs.Lookup("log-level").Value.(flag.Getter).Get(); got != ErrorLevel {
+		t.Errorf("Expected Get() = %v, got %v", ErrorLevel, got)
+	}
+}
+
+// Logger must keep satisfying the gsr.Logger interface
+var _ gsr.Logger = (*Logger)(nil)
+
+func TestLevelOrder(t *testing.T) {
+	logger, logs := newObservedLogger(NoticeLevel)
+
+	logger.Info("info")
+	logger.Notice("notice")
+	logger.Warn("warn")
+	logger.Critical("critical")
+	logger.Emergency("emergency")
+
+	var got []string
+	for _, e := range logs.All() {
+		got = append(got, e.Message)
+	}
+	want := "notice,warn,critical,emergency"
+	if strings.Join(got, ",") != want {
+		t.Errorf("Expected %s, got %v", want, got)
+	}
+}
+
+func TestLevelEncoding(t *testing.T) {
+	var buf bytes.Buffer
+	encoderConfig := zapcore.EncoderConfig{
+		MessageKey:  "msg",
+		LevelKey:    "level",
+		EncodeLevel: LowercaseLevelEncoder,
+	}
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(encoderConfig), zapcore.AddSync(&buf), allLevels)
+	logger := newCoreLogger(core, newLevelSet(TraceLevel))
+
+	logger.Trace("m")
+	logger.Notice("m")
+	logger.Critical("m")
+	logger.Alert("m")
+	logger.Emergency("m")
+
+	for _, name := range []string{"trace", "notice", "critical", "alert", "emergency"} {
+		if !strings.Contains(buf.String(), `"level":"`+name+`"`) {
+			t.Errorf("Expected level %q in output %s", name, buf.String())
+		}
+	}
+}
+
+func TestLevelStacktrace(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "app.log")
+	logger, err := NewLoggerWithConfig(Config{
+		Level:       InfoLevel,
+		Encoding:    "json",
+		OutputPaths: []string{filename},
+	})
+	if err != nil {
+		t.Fatalf("NewLoggerWithConfig failed: %v", err)
+	}
+
+	logger.Notice("notice without stack")
+	logger.Critical("critical with stack")
+	logger.Close()
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %d", len(lines))
+	}
+	if strings.Contains(lines[0], "stacktrace") || !strings.Contains(lines[0], `"level":"notice"`) {
+		t.Errorf("Unexpected notice line %s", lines[0])
+	}
+	if !strings.Contains(lines[1], "stacktrace") || !strings.Contains(lines[1], `"level":"critical"`) {
+		t.Errorf("Unexpected critical line %s", lines[1])
+	}
+}
+
+func TestSyslogSeverity(t *testing.T) {
+	tests := []struct {
+		level Level
+		want  int
+	}{
+		{TraceLevel, 7},
+		{DebugLevel, 7},
+		{InfoLevel, 6},
+		{NoticeLevel, 5},
+		{WarnLevel, 4},
+		{ErrorLevel, 3},
+		{CriticalLevel, 2},
+		{AlertLevel, 1},
+		{EmergencyLevel, 0},
+		{FatalLevel, 2},
+		{PanicLevel, 2},
+	}
+
+	for _, tt := range tests {
+		if got := tt.level.SyslogSeverity(); got != tt.want {
+			t.Errorf("%v.SyslogSeverity() = %d, want %d", tt.level, got, tt.want)
+		}
+	}
+}
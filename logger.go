@@ -1,6 +1,11 @@
 package golog
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
 	"github.com/muleiwu/gsr"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -10,15 +15,26 @@ import (
 type Level int8
 
 const (
+	// TraceLevel logs are finer-grained than Debug and are usually disabled.
+	TraceLevel Level = iota - 2
 	// DebugLevel logs are typically voluminous, and are usually disabled in production.
-	DebugLevel Level = iota - 1
+	DebugLevel
 	// InfoLevel is the default logging priority.
 	InfoLevel
+	// NoticeLevel logs are normal but significant events that deserve more
+	// attention than Info.
+	NoticeLevel
 	// WarnLevel logs are more important than Info, but don't need individual human review.
 	WarnLevel
 	// ErrorLevel logs are high-priority. If an application is running smoothly,
 	// it shouldn't generate any error-level logs.
 	ErrorLevel
+	// CriticalLevel logs critical conditions, such as a failed hard dependency.
+	CriticalLevel
+	// AlertLevel logs conditions that need action to be taken immediately.
+	AlertLevel
+	// EmergencyLevel logs that the system is unusable.
+	EmergencyLevel
 	// FatalLevel logs a message, then calls os.Exit(1).
 	FatalLevel
 	// PanicLevel logs a message, then panics.
@@ -28,14 +44,24 @@ const (
 // String returns a lower-case ASCII representation of the log level.
 func (l Level) String() string {
 	switch l {
+	case TraceLevel:
+		return "trace"
 	case DebugLevel:
 		return "debug"
 	case InfoLevel:
 		return "info"
+	case NoticeLevel:
+		return "notice"
 	case WarnLevel:
 		return "warn"
 	case ErrorLevel:
 		return "error"
+	case CriticalLevel:
+		return "critical"
+	case AlertLevel:
+		return "alert"
+	case EmergencyLevel:
+		return "emergency"
 	case FatalLevel:
 		return "fatal"
 	case PanicLevel:
@@ -48,14 +74,24 @@ func (l Level) String() string {
 // toZapLevel converts our Level to zapcore.Level
 func (l Level) toZapLevel() zapcore.Level {
 	switch l {
+	case TraceLevel:
+		return zapTraceLevel
 	case DebugLevel:
 		return zapcore.DebugLevel
 	case InfoLevel:
 		return zapcore.InfoLevel
+	case NoticeLevel:
+		return zapNoticeLevel
 	case WarnLevel:
 		return zapcore.WarnLevel
 	case ErrorLevel:
 		return zapcore.ErrorLevel
+	case CriticalLevel:
+		return zapCriticalLevel
+	case AlertLevel:
+		return zapAlertLevel
+	case EmergencyLevel:
+		return zapEmergencyLevel
 	case FatalLevel:
 		return zapcore.FatalLevel
 	case PanicLevel:
@@ -65,103 +101,345 @@ func (l Level) toZapLevel() zapcore.Level {
 	}
 }
 
+// fromZapLevel converts a zapcore.Level to our Level
+func fromZapLevel(l zapcore.Level) Level {
+	switch l {
+	case zapTraceLevel:
+		return TraceLevel
+	case zapcore.DebugLevel:
+		return DebugLevel
+	case zapcore.InfoLevel:
+		return InfoLevel
+	case zapNoticeLevel:
+		return NoticeLevel
+	case zapcore.WarnLevel:
+		return WarnLevel
+	case zapcore.ErrorLevel:
+		return ErrorLevel
+	case zapCriticalLevel, zapcore.DPanicLevel:
+		return CriticalLevel
+	case zapAlertLevel:
+		return AlertLevel
+	case zapEmergencyLevel:
+		return EmergencyLevel
+	case zapcore.FatalLevel:
+		return FatalLevel
+	case zapcore.PanicLevel:
+		return PanicLevel
+	default:
+		if l < zapTraceLevel {
+			return TraceLevel
+		}
+		return InfoLevel
+	}
+}
+
 // Logger wraps zap.Logger and implements the gsr.Logger interface
 type Logger struct {
 	logger *zap.Logger
+	// levels holds the global level and per-name overrides; shared with children
+	levels *levelSet
+	// verbosity holds the V level and vmodule filters; shared with children
+	verbosity *verbosity
+	// callerSkip is the number of wrapper frames above golog, from Config.CallerSkip
+	callerSkip int
+	// sinks holds the outputs opened by NewLoggerWithConfig; shared with children
+	sinks *sinkSet
+	// config holds the state built by NewLoggerWithConfig for ApplyConfig;
+	// shared with children
+	config *configState
 }
 
 // Config holds the configuration for creating a new logger
 type Config struct {
 	// Level sets the minimum enabled logging level
-	Level Level
+	Level Level `json:"level" yaml:"level"`
 	// Development puts the logger in development mode
-	Development bool
-	// Encoding sets the logger's encoding (json or console)
-	Encoding string
+	Development bool `json:"development" yaml:"development"`
+	// Encoding sets the logger's encoding: json, console, or combined for
+	// Apache combined log format access lines from HTTPMiddleware. combined
+	// drops every other entry, so it is only valid in Outputs next to an
+	// output with another encoding.
+	Encoding string `json:"encoding" yaml:"encoding"`
+	// Encoder sets output keys and the encoding of times, durations and levels
+	Encoder EncoderConfig `json:"encoder" yaml:"encoder"`
 	// OutputPaths is a list of URLs or file paths to write logging output to
-	OutputPaths []string
+	OutputPaths []string `json:"outputPaths" yaml:"outputPaths"`
 	// ErrorOutputPaths is a list of URLs to write internal logger errors to
-	ErrorOutputPaths []string
+	ErrorOutputPaths []string `json:"errorOutputPaths" yaml:"errorOutputPaths"`
+	// InitialFields are added to every entry, e.g. {"region": "eu-west-1"}
+	InitialFields map[string]any `json:"initialFields" yaml:"initialFields"`
+	// Metadata, if set, adds the service name, version, environment,
+	// hostname and pid to every entry. Default: nil (no metadata)
+	Metadata *MetadataConfig `json:"metadata" yaml:"metadata"`
+	// Kubernetes, if set, adds the pod name, namespace, node and container
+	// from the downward API to every entry as a "k8s" object. Default: nil
+	Kubernetes *KubernetesConfig `json:"kubernetes" yaml:"kubernetes"`
 	// CallerSkip increases the number of callers skipped by caller annotation
 	// Default is 0, which will be automatically set to 1 (skip golog wrapper).
 	// Set to 1+ if you wrap golog in your own logger (1 = single wrap, 2 = double wrap, etc.).
-	CallerSkip uint
+	CallerSkip uint `json:"callerSkip" yaml:"callerSkip"`
 	// DisableCallerTrim disables trimming of the caller path.
 	// When true, shows full path from module root (e.g., pkg/service/cron/service/cron_server.go:67)
 	// When false (default), shows shortened path (e.g., service/cron_server.go:67)
-	DisableCallerTrim bool
+	DisableCallerTrim bool `json:"disableCallerTrim" yaml:"disableCallerTrim"`
+	// StacktraceLevel is the lowest level at which entries carry a stack trace.
+	// Default: nil (ErrorLevel, or WarnLevel in development)
+	StacktraceLevel *Level `json:"stacktraceLevel" yaml:"stacktraceLevel"`
+	// DisableStacktrace stops stack traces from being captured at any level
+	DisableStacktrace bool `json:"disableStacktrace" yaml:"disableStacktrace"`
+	// StacktraceDepth limits stack traces to that many frames, starting from
+	// the caller. Default: 0 (no limit). Frame paths are trimmed like the
+	// caller unless DisableCallerTrim is set.
+	StacktraceDepth int `json:"stacktraceDepth" yaml:"stacktraceDepth"`
+	// Verbosity is the global level for V; V(n) logs when n <= Verbosity
+	Verbosity int `json:"verbosity" yaml:"verbosity"`
+	// VModule sets V levels per source file, e.g. "service/cron*=3,db=2".
+	// Patterns without a slash match the file's base name; patterns with
+	// slashes match the same number of trailing path elements. The .go suffix
+	// is ignored.
+	VModule string `json:"vmodule" yaml:"vmodule"`
+	// NamedLevels overrides Level for loggers created with Named, keyed by name
+	// pattern, e.g. {"billing.*": DebugLevel, "db": WarnLevel}
+	NamedLevels map[string]Level `json:"namedLevels" yaml:"namedLevels"`
+	// Outputs replaces OutputPaths with several outputs that each have their
+	// own level and encoding, e.g. colored console at Debug to stdout and JSON
+	// at Info to a file. Encoding and Rotate are the defaults for outputs that
+	// don't set their own.
+	Outputs []OutputConfig `json:"outputs" yaml:"outputs"`
+	// Sampling limits how many entries with the same level and message are
+	// logged per second. Default: nil (no sampling)
+	Sampling *SamplingConfig `json:"sampling" yaml:"sampling"`
+	// SplitStderr replaces OutputPaths with stdout for entries below WarnLevel
+	// and stderr for WarnLevel and above, for platforms that treat stderr as
+	// errors. It can't be combined with Outputs.
+	SplitStderr bool `json:"splitStderr" yaml:"splitStderr"`
+	// Rotate enables size- and age-based rotation for every file in OutputPaths.
+	// Individual outputs can also opt in with the rotate:// scheme,
+	// e.g. rotate:///var/log/app.log?max_size=100&max_backups=5
+	Rotate *RotateConfig `json:"rotate" yaml:"rotate"`
 }
 
 // NewLogger creates a new logger with example configuration (for testing only)
 func NewLogger() *Logger {
+	// Same as zap.NewExample, but with an adjustable level
+	levels := newLevelSet(DebugLevel)
+	encoderConfig := zapcore.EncoderConfig{
+		MessageKey:     "msg",
+		LevelKey:       "level",
+		NameKey:        "logger",
+		EncodeLevel:    LowercaseLevelEncoder,
+		EncodeTime:     zapcore.ISO8601TimeEncoder,
+		EncodeDuration: zapcore.StringDurationEncoder,
+	}
+	core := newIOCore(zapcore.NewJSONEncoder(encoderConfig), os.Stdout, allLevels)
+
 	// Add caller skip to show correct file and line number
-	logger := zap.NewExample(zap.AddCallerSkip(1))
+	return newCoreLogger(core, levels, zap.AddCallerSkip(1))
+}
+
+// newCoreLogger creates a logger that filters entries for core with levels.
+// core must be built by golog, as it receives golog's extra levels as is.
+func newCoreLogger(core zapcore.Core, levels *levelSet, opts ...zap.Option) *Logger {
 	return &Logger{
-		logger: logger,
+		logger:    zap.New(&levelCore{Core: core, levels: levels}, opts...),
+		levels:    levels,
+		verbosity: &verbosity{},
 	}
 }
 
 // NewDevelopmentLogger creates a logger suitable for development
 // with human-readable console output and debug-level logging
 func NewDevelopmentLogger() (*Logger, error) {
-	logger, err := zap.NewDevelopment(zap.AddCallerSkip(1))
-	if err != nil {
-		return nil, err
-	}
-	return &Logger{logger: logger}, nil
+	return newPresetLogger(zap.NewDevelopmentConfig())
 }
 
 // NewProductionLogger creates a logger suitable for production
-// with JSON output and info-level logging
+// with JSON output and info-level logging. Like zap's production preset, it
+// samples entries: each second, the first 100 entries with the same level and
+// message are logged, then every 100th. Use NewLoggerWithConfig with
+// Config.Sampling to choose other rates.
 func NewProductionLogger() (*Logger, error) {
-	logger, err := zap.NewProduction(zap.AddCallerSkip(1))
+	return newPresetLogger(zap.NewProductionConfig())
+}
+
+// newPresetLogger builds a logger from one of zap's preset configurations,
+// moving level filtering into a levelCore so it can be changed at runtime
+// and replacing zap's level handling with one that knows golog's extra levels
+func newPresetLogger(zapConfig zap.Config) (*Logger, error) {
+	levels := newLevelSet(fromZapLevel(zapConfig.Level.Level()))
+
+	stacktraceLevel, encodeLevel := ErrorLevel, LowercaseLevelEncoder
+	if zapConfig.Development {
+		stacktraceLevel, encodeLevel = WarnLevel, CapitalLevelEncoder
+	}
+	zapConfig.EncoderConfig.EncodeLevel = encodeLevel
+
+	// zap's sampler only counts zap's own levels, so sample with ours
+	var sampling *SamplingConfig
+	if zapConfig.Sampling != nil {
+		sampling = &SamplingConfig{
+			Initial:    zapConfig.Sampling.Initial,
+			Thereafter: zapConfig.Sampling.Thereafter,
+			Tick:       time.Second,
+		}
+	}
+
+	// zapConfig.Build would write through zap's own core, which syncs after
+	// every entry it considers above Error, Notice included
+	encoder := zapcore.NewJSONEncoder(zapConfig.EncoderConfig)
+	if zapConfig.Encoding == "console" {
+		encoder = zapcore.NewConsoleEncoder(zapConfig.EncoderConfig)
+	}
+	sink, closeSink, err := zap.Open(zapConfig.OutputPaths...)
 	if err != nil {
 		return nil, err
 	}
-	return &Logger{logger: logger}, nil
+	errSink, _, err := zap.Open(zapConfig.ErrorOutputPaths...)
+	if err != nil {
+		closeSink()
+		return nil, err
+	}
+
+	var core zapcore.Core = newIOCore(encoder, sink, allLevels)
+	if sampling != nil {
+		core = newSamplingCore(core, *sampling)
+	}
+	opts := []zap.Option{
+		zap.ErrorOutput(errSink),
+		zap.AddCaller(),
+		zap.AddCallerSkip(1),
+		zap.AddStacktrace(stacktraceLevel),
+	}
+	if zapConfig.Development {
+		opts = append(opts, zap.Development())
+	}
+	return newCoreLogger(core, levels, opts...), nil
 }
 
-// NewLoggerWithConfig creates a new logger with custom configuration
+// NewLoggerWithConfig creates a new logger with custom configuration.
+// config is checked with Validate first, so every problem is reported at once.
+// The logger can be reconfigured later with ApplyConfig.
 func NewLoggerWithConfig(config Config) (*Logger, error) {
-	// Create encoder config based on development mode
-	var encoderConfig zapcore.EncoderConfig
-	if config.Development {
-		encoderConfig = zap.NewDevelopmentEncoderConfig()
-	} else {
-		encoderConfig = zap.NewProductionEncoderConfig()
+	if err := config.Validate(); err != nil {
+		return nil, err
 	}
 
-	// Configure caller encoder based on DisableCallerTrim
-	if config.DisableCallerTrim {
-		encoderConfig.EncodeCaller = zapcore.FullCallerEncoder
+	// Open outputs ourselves rather than through zap.Config so that file
+	// paths can be routed through a RotatingWriter
+	sinks := &sinkSet{}
+	errSink, err := sinks.open(config.ErrorOutputPaths, nil)
+	if err != nil {
+		sinks.Close()
+		return nil, err
 	}
+	sinks.errorOutput = errSink
 
-	zapConfig := zap.Config{
-		Level:            zap.NewAtomicLevelAt(config.Level.toZapLevel()),
-		Development:      config.Development,
-		Encoding:         config.Encoding,
-		EncoderConfig:    encoderConfig,
-		OutputPaths:      config.OutputPaths,
-		ErrorOutputPaths: config.ErrorOutputPaths,
+	state, err := newConfigState(config, errSink)
+	if err != nil {
+		sinks.Close()
+		return nil, err
 	}
+	sinks.closers = append(sinks.closers, state)
+
+	core := &levelCore{Core: &reloadCore{state: state}, levels: state.levels}
 
 	// Use configured caller skip + 1 (for golog wrapper)
 	// If CallerSkip is 0 (default), this results in 1 (same as preset loggers)
 	// If CallerSkip is 1+, it adds 1 for the golog wrapper layer
 	callerSkip := config.CallerSkip
 
-	logger, err := zapConfig.Build(zap.AddCallerSkip(int(callerSkip + 1)))
-	if err != nil {
-		return nil, err
+	opts := []zap.Option{
+		zap.ErrorOutput(errSink),
+		zap.AddCaller(),
+		zap.AddCallerSkip(int(callerSkip + 1)),
+		zap.AddStacktrace(zap.LevelEnablerFunc(state.stacktraceEnabled)),
+	}
+	if config.Development {
+		opts = append(opts, zap.Development())
 	}
 
-	return &Logger{logger: logger}, nil
+	return &Logger{
+		logger:     zap.New(core, opts...),
+		levels:     state.levels,
+		verbosity:  state.verbosity,
+		callerSkip: int(callerSkip),
+		sinks:      sinks,
+		config:     state,
+	}, nil
+}
+
+// newEncoder creates the zapcore.Encoder named by encoding
+func newEncoder(encoding string, encoderConfig zapcore.EncoderConfig) (zapcore.Encoder, error) {
+	switch encoding {
+	case "json":
+		return zapcore.NewJSONEncoder(encoderConfig), nil
+	case "console":
+		return zapcore.NewConsoleEncoder(encoderConfig), nil
+	case "combined":
+		return newCombinedEncoder(), nil
+	case "":
+		return nil, errors.New("golog: no encoding specified")
+	default:
+		return nil, fmt.Errorf("golog: unknown encoding %q", encoding)
+	}
 }
 
 // NewLoggerWithZap creates a logger from an existing zap.Logger
+// Note: zapLogger's core receives golog's extra levels as the nearest zap
+// level: Trace as Debug, Notice as Info and Critical, Alert and Emergency as
+// Error
 // Note: If you need caller skip, pass a logger with AddCallerSkip already configured
+// Note: SetLevel can only make the logger less verbose than zapLogger's own level
 func NewLoggerWithZap(zapLogger *zap.Logger) *Logger {
-	return &Logger{logger: zapLogger}
+	levels := newLevelSet(fromZapLevel(zapLogger.Level()))
+	logger := zapLogger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &levelCore{Core: &zapLevelCore{Core: core}, levels: levels}
+	}))
+	return &Logger{logger: logger, levels: levels, verbosity: &verbosity{}}
+}
+
+// SetLevel changes the global minimum enabled level at runtime.
+// The change applies to this logger, its parent and every child created
+// with With, WithZapFields or Named, as they all share one level.
+// Overrides set with SetNamedLevel still take precedence.
+func (l *Logger) SetLevel(level Level) {
+	l.levels.setGlobal(level)
+}
+
+// Level returns the minimum enabled level for this logger's name, taking
+// overrides set with SetNamedLevel into account
+func (l *Logger) Level() Level {
+	return l.levels.levelFor(l.logger.Name())
+}
+
+// SetNamedLevel overrides the level for loggers created with Named whose
+// name matches pattern. "billing.cron" matches only that name, "billing.*"
+// matches "billing" and every name below it, and "*" matches every name.
+// The most specific matching pattern wins.
+//
+// Example:
+//
+//	logger.SetNamedLevel("billing.*", golog.DebugLevel)
+func (l *Logger) SetNamedLevel(pattern string, level Level) {
+	l.levels.set(pattern, level)
+}
+
+// ClearNamedLevel removes the override set for pattern
+func (l *Logger) ClearNamedLevel(pattern string) {
+	l.levels.clear(pattern)
+}
+
+// SetNamedLevels replaces all overrides with those in spec, a
+// comma-separated list of pattern=level pairs such as "billing.*=debug,db=warn".
+// An empty spec removes every override.
+func (l *Logger) SetNamedLevels(spec string) error {
+	overrides, err := parseLevelSpec(spec)
+	if err != nil {
+		return err
+	}
+	l.levels.replace(overrides)
+	return nil
 }
 
 // getFields converts gsr.LoggerField to zap.Field
@@ -178,6 +456,11 @@ func (l *Logger) getFields(args ...gsr.LoggerField) []zap.Field {
 	return fields
 }
 
+// Trace logs a message at TraceLevel
+func (l *Logger) Trace(format string, args ...gsr.LoggerField) {
+	l.logger.Log(zapTraceLevel, format, l.getFields(args...)...)
+}
+
 // Debug logs a message at DebugLevel
 func (l *Logger) Debug(format string, args ...gsr.LoggerField) {
 	l.logger.Debug(format, l.getFields(args...)...)
@@ -188,10 +471,9 @@ func (l *Logger) Info(format string, args ...gsr.LoggerField) {
 	l.logger.Info(format, l.getFields(args...)...)
 }
 
-// Notice logs a message at InfoLevel (alias for Info)
-// Notice level is mapped to Info as zap doesn't have a separate Notice level
+// Notice logs a message at NoticeLevel
 func (l *Logger) Notice(format string, args ...gsr.LoggerField) {
-	l.logger.Info(format, l.getFields(args...)...)
+	l.logger.Log(zapNoticeLevel, format, l.getFields(args...)...)
 }
 
 // Warn logs a message at WarnLevel
@@ -204,6 +486,21 @@ func (l *Logger) Error(format string, args ...gsr.LoggerField) {
 	l.logger.Error(format, l.getFields(args...)...)
 }
 
+// Critical logs a message at CriticalLevel
+func (l *Logger) Critical(format string, args ...gsr.LoggerField) {
+	l.logger.Log(zapCriticalLevel, format, l.getFields(args...)...)
+}
+
+// Alert logs a message at AlertLevel
+func (l *Logger) Alert(format string, args ...gsr.LoggerField) {
+	l.logger.Log(zapAlertLevel, format, l.getFields(args...)...)
+}
+
+// Emergency logs a message at EmergencyLevel
+func (l *Logger) Emergency(format string, args ...gsr.LoggerField) {
+	l.logger.Log(zapEmergencyLevel, format, l.getFields(args...)...)
+}
+
 // Fatal logs a message at FatalLevel and then calls os.Exit(1)
 func (l *Logger) Fatal(format string, args ...gsr.LoggerField) {
 	l.logger.Fatal(format, l.getFields(args...)...)
@@ -233,6 +530,43 @@ func (l *Logger) Sync() error {
 	return err
 }
 
+// Close flushes buffered entries and closes any files opened by the logger.
+// Child loggers share these files, so Close should only be called on the
+// root logger once it is no longer in use.
+func (l *Logger) Close() error {
+	err := l.Sync()
+	if l.sinks != nil {
+		err = errors.Join(err, l.sinks.Close())
+	}
+	return err
+}
+
+// Reopen closes and reopens every file opened from Config.OutputPaths and
+// Config.ErrorOutputPaths. Call it after an external tool such as logrotate
+// (in create mode) has moved the files away, so logging continues in new files.
+// Loggers not created by NewLoggerWithConfig have no files to reopen.
+func (l *Logger) Reopen() error {
+	if l.sinks == nil {
+		return nil
+	}
+	return l.sinks.Reopen()
+}
+
+// ReopenOnSignal reopens the logger's files whenever one of sigs is received
+// (SIGHUP if none are given) until the returned stop function is called or
+// the logger is closed.
+//
+// Example:
+//
+//	stop := logger.ReopenOnSignal()
+//	defer stop()
+func (l *Logger) ReopenOnSignal(sigs ...os.Signal) (stop func()) {
+	if l.sinks == nil {
+		return func() {}
+	}
+	return l.sinks.reopenOnSignal(sigs...)
+}
+
 // containsAny checks if s contains any of the substrings
 func containsAny(s string, substrs ...string) bool {
 	for _, substr := range substrs {
@@ -249,16 +583,31 @@ func containsAny(s string, substrs ...string) bool {
 
 // With creates a child logger with additional fields
 func (l *Logger) With(args ...gsr.LoggerField) *Logger {
-	return &Logger{
-		logger: l.logger.With(l.getFields(args...)...),
-	}
+	return l.clone(l.logger.With(l.getFields(args...)...))
 }
 
 // WithZapFields creates a child logger with additional zap fields
 func (l *Logger) WithZapFields(fields ...zap.Field) *Logger {
-	return &Logger{
-		logger: l.logger.With(fields...),
-	}
+	return l.clone(l.logger.With(fields...))
+}
+
+// clone returns a copy of l that logs through zapLogger and shares
+// everything else with l
+func (l *Logger) clone(zapLogger *zap.Logger) *Logger {
+	child := *l
+	child.logger = zapLogger
+	return &child
+}
+
+// Named creates a child logger with name appended to the logger's name,
+// separated by a period. The name appears in output and can be targeted by
+// SetNamedLevel.
+//
+// Example:
+//
+//	cron := logger.Named("billing").Named("cron") // "billing.cron"
+func (l *Logger) Named(name string) *Logger {
+	return l.clone(l.logger.Named(name))
 }
 
 // GetZapLogger returns the underlying zap.Logger
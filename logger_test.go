@@ -1,7 +1,14 @@
 package golog
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func TestNewLogger(t *testing.T) {
@@ -68,11 +75,15 @@ func TestLoggerLevels(t *testing.T) {
 	defer logger.Sync()
 
 	// Test all log levels (except Fatal and Panic which would terminate/panic)
+	logger.Trace("trace message", Field("key", "value"))
 	logger.Debug("debug message", Field("key", "value"))
 	logger.Info("info message", Field("key", "value"))
 	logger.Notice("notice message", Field("key", "value"))
 	logger.Warn("warn message", Field("key", "value"))
 	logger.Error("error message", Field("key", "value"))
+	logger.Critical("critical message", Field("key", "value"))
+	logger.Alert("alert message", Field("key", "value"))
+	logger.Emergency("emergency message", Field("key", "value"))
 
 	// Test without fields
 	logger.Info("message without fields")
@@ -170,10 +181,15 @@ func TestLevel(t *testing.T) {
 		level    Level
 		expected string
 	}{
+		{TraceLevel, "trace"},
 		{DebugLevel, "debug"},
 		{InfoLevel, "info"},
+		{NoticeLevel, "notice"},
 		{WarnLevel, "warn"},
 		{ErrorLevel, "error"},
+		{CriticalLevel, "critical"},
+		{AlertLevel, "alert"},
+		{EmergencyLevel, "emergency"},
 		{FatalLevel, "fatal"},
 		{PanicLevel, "panic"},
 	}
@@ -318,3 +334,68 @@ func TestConfigWithCallerSkip(t *testing.T) {
 	logger1.Info("test with default caller skip (0+1=1)")
 	logger2.Info("test with custom caller skip (1+1=2)")
 }
+
+func TestSetLevel(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "app.log")
+	logger, err := NewLoggerWithConfig(Config{
+		Level:       InfoLevel,
+		Encoding:    "json",
+		OutputPaths: []string{filename},
+	})
+	if err != nil {
+		t.Fatalf("NewLoggerWithConfig failed: %v", err)
+	}
+	defer logger.Close()
+
+	child := logger.With(Field("component", "child"))
+	child.Debug("hidden debug")
+
+	logger.SetLevel(DebugLevel)
+	if got := child.Level(); got != DebugLevel {
+		t.Errorf("Expected child level %v, got %v", DebugLevel, got)
+	}
+	child.Debug("visible debug")
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if strings.Contains(string(data), "hidden debug") {
+		t.Error("Debug entry logged before SetLevel(DebugLevel)")
+	}
+	if !strings.Contains(string(data), "visible debug") {
+		t.Error("Debug entry not logged after SetLevel(DebugLevel)")
+	}
+}
+
+func TestSetLevelPresets(t *testing.T) {
+	dev, err := NewDevelopmentLogger()
+	if err != nil {
+		t.Fatalf("NewDevelopmentLogger failed: %v", err)
+	}
+	if got := dev.Level(); got != DebugLevel {
+		t.Errorf("Expected development level %v, got %v", DebugLevel, got)
+	}
+
+	prod, err := NewProductionLogger()
+	if err != nil {
+		t.Fatalf("NewProductionLogger failed: %v", err)
+	}
+	prod.SetLevel(ErrorLevel)
+	if prod.GetZapLogger().Core().Enabled(zapcore.WarnLevel) {
+		t.Error("Warn still enabled after SetLevel(ErrorLevel)")
+	}
+}
+
+func TestSetLevelWithZap(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	logger := NewLoggerWithZap(zap.New(core))
+
+	logger.SetLevel(WarnLevel)
+	logger.Info("dropped")
+	logger.Warn("kept")
+
+	if logs.Len() != 1 || logs.All()[0].Message != "kept" {
+		t.Errorf("Expected only the warn entry, got %v", logs.All())
+	}
+}
@@ -0,0 +1,110 @@
+package golog
+
+import (
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// MetadataConfig adds fields describing the running process to every entry:
+// "service", "version", "env", "hostname" and "pid". Fields left empty are
+// filled in from the executable and its build info where possible, and
+// omitted otherwise.
+//
+// Example:
+//
+//	config.Metadata = &golog.MetadataConfig{Environment: os.Getenv("APP_ENV")}
+type MetadataConfig struct {
+	// Service is the service name. Default: the executable's base name
+	Service string `json:"service" yaml:"service"`
+	// Version is the service version. Default: the main module version, or
+	// the VCS revision when built from a checkout, from debug.ReadBuildInfo
+	Version string `json:"version" yaml:"version"`
+	// Environment is the deployment environment, e.g. "production"
+	Environment string `json:"environment" yaml:"environment"`
+}
+
+// fields returns the metadata fields, filling in defaults from the
+// executable, its build info and the OS
+func (c MetadataConfig) fields() []zapcore.Field {
+	service, version := c.Service, c.Version
+	if service == "" {
+		service = strings.TrimSuffix(filepath.Base(os.Args[0]), ".exe")
+	}
+	if version == "" {
+		version = buildVersion()
+	}
+
+	var fields []zapcore.Field
+	if service != "" {
+		fields = append(fields, zap.String("service", service))
+	}
+	if version != "" {
+		fields = append(fields, zap.String("version", version))
+	}
+	if c.Environment != "" {
+		fields = append(fields, zap.String("env", c.Environment))
+	}
+	if hostname, err := os.Hostname(); err == nil {
+		fields = append(fields, zap.String("hostname", hostname))
+	}
+	return append(fields, zap.Int("pid", os.Getpid()))
+}
+
+// buildVersion returns the main module version, or the VCS revision if the
+// binary was built from a checkout without a version
+func buildVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	if v := info.Main.Version; v != "" && v != "(devel)" {
+		return v
+	}
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" {
+			if len(setting.Value) > 12 {
+				return setting.Value[:12]
+			}
+			return setting.Value
+		}
+	}
+	return ""
+}
+
+// initialFields returns the fields added to every entry: Metadata,
+// Kubernetes, then InitialFields sorted by key. InitialFields take precedence
+// over metadata fields with the same key.
+func (c Config) initialFields() []zapcore.Field {
+	var metadata []zapcore.Field
+	if c.Metadata != nil {
+		metadata = c.Metadata.fields()
+	}
+	if c.Kubernetes != nil {
+		if f, ok := c.Kubernetes.field(); ok {
+			metadata = append(metadata, f)
+		}
+	}
+
+	var fields []zapcore.Field
+	for _, f := range metadata {
+		if _, ok := c.InitialFields[f.Key]; !ok {
+			fields = append(fields, f)
+		}
+	}
+
+	keys := make([]string, 0, len(c.InitialFields))
+	for key := range c.InitialFields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fields = append(fields, zap.Any(key, c.InitialFields[key]))
+	}
+	return fields
+}
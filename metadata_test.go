@@ -0,0 +1,148 @@
+package golog
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInitialFields(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "app.log")
+	config := DefaultConfig()
+	config.OutputPaths = []string{filename}
+	config.InitialFields = map[string]any{"region": "eu-west-1", "shard": 3}
+	logger := newFileLogger(t, config)
+
+	logger.Info("hello")
+	logger.With(Field("request_id", "abc")).Info("child")
+	logger.Sync()
+
+	lines := strings.Split(strings.TrimSpace(readFile(t, filename)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(lines))
+	}
+	for _, line := range lines {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Unmarshal failed: %v", err)
+		}
+		if entry["region"] != "eu-west-1" || entry["shard"] != float64(3) {
+			t.Errorf("Expected initial fields in %v", entry)
+		}
+	}
+	if !strings.Contains(lines[1], `"request_id":"abc"`) {
+		t.Errorf("Expected child fields to be kept, got %s", lines[1])
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "app.log")
+	config := DefaultConfig()
+	config.OutputPaths = []string{filename}
+	config.Metadata = &MetadataConfig{Version: "v1.2.3", Environment: "staging"}
+	config.InitialFields = map[string]any{"service": "billing"}
+	logger := newFileLogger(t, config)
+
+	logger.Info("hello")
+	logger.Sync()
+
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(readFile(t, filename)), &entry); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	hostname, _ := os.Hostname()
+	want := map[string]any{
+		"service":  "billing",
+		"version":  "v1.2.3",
+		"env":      "staging",
+		"hostname": hostname,
+		"pid":      float64(os.Getpid()),
+	}
+	for key, value := range want {
+		if entry[key] != value {
+			t.Errorf("Expected %s=%v, got %v", key, value, entry[key])
+		}
+	}
+	if n := strings.Count(readFile(t, filename), `"service"`); n != 1 {
+		t.Errorf("Expected InitialFields to replace the service field, got it %d times", n)
+	}
+}
+
+func TestMetadataDefaults(t *testing.T) {
+	fields := MetadataConfig{}.fields()
+	keys := make([]string, len(fields))
+	for i, f := range fields {
+		keys[i] = f.Key
+	}
+	if keys[0] != "service" || fields[0].String != strings.TrimSuffix(filepath.Base(os.Args[0]), ".exe") {
+		t.Errorf("Expected the executable name as service, got %v", fields[0])
+	}
+	if got := strings.Join(keys, ","); strings.Contains(got, "env") || !strings.HasSuffix(got, "hostname,pid") {
+		t.Errorf("Unexpected metadata fields %s", got)
+	}
+}
+
+func TestInitialFieldsApplyConfig(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "app.log")
+	config := DefaultConfig()
+	config.OutputPaths = []string{filename}
+	config.InitialFields = map[string]any{"deploy": "blue"}
+	logger := newFileLogger(t, config)
+	child := logger.With(Field("component", "api"))
+
+	config.InitialFields = map[string]any{"deploy": "green"}
+	if err := logger.ApplyConfig(config); err != nil {
+		t.Fatalf("ApplyConfig failed: %v", err)
+	}
+	child.Info("after")
+	logger.Sync()
+
+	got := readFile(t, filename)
+	if !strings.Contains(got, `"deploy":"green"`) || strings.Contains(got, `"deploy":"blue"`) || !strings.Contains(got, `"component":"api"`) {
+		t.Errorf("Expected the new initial fields on the child, got %s", got)
+	}
+}
+
+func TestInitialFieldsFromConfig(t *testing.T) {
+	path := writeConfigFile(t, "log.yaml", `
+initialFields:
+  region: eu-west-1
+metadata:
+  environment: production
+`)
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if config.InitialFields["region"] != "eu-west-1" || config.Metadata == nil || config.Metadata.Environment != "production" {
+		t.Errorf("Unexpected InitialFields %v and Metadata %+v", config.InitialFields, config.Metadata)
+	}
+
+	t.Setenv("GOLOG_INITIAL_FIELDS", "region=us-east-1, team=payments")
+	t.Setenv("GOLOG_METADATA_SERVICE", "billing")
+	config, err = ConfigFromEnv("GOLOG")
+	if err != nil {
+		t.Fatalf("ConfigFromEnv failed: %v", err)
+	}
+	if config.InitialFields["region"] != "us-east-1" || config.InitialFields["team"] != "payments" {
+		t.Errorf("Unexpected InitialFields %v", config.InitialFields)
+	}
+	if config.Metadata == nil || config.Metadata.Service != "billing" {
+		t.Errorf("Unexpected Metadata %+v", config.Metadata)
+	}
+
+	t.Setenv("GOLOG_INITIAL_FIELDS", "region")
+	if _, err := ConfigFromEnv("GOLOG"); err == nil {
+		t.Error("Expected an error for a field without a value")
+	}
+}
+
+func TestInitialFieldsValidate(t *testing.T) {
+	config := DefaultConfig()
+	config.InitialFields = map[string]any{" ": 1}
+	if err := config.Validate(); err == nil || !strings.Contains(err.Error(), "initialFields") {
+		t.Errorf("Expected an initialFields error, got %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+package golog
+
+import (
+	"reflect"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// OutputConfig describes one of several outputs set with Config.Outputs,
+// each with its own level and encoding
+type OutputConfig struct {
+	// Paths is a list of URLs or file paths to write to, as in Config.OutputPaths
+	Paths []string `json:"paths" yaml:"paths"`
+	// Level is the minimum level written to this output. Entries must also
+	// be enabled by Config.Level or Config.NamedLevels.
+	Level Level `json:"level" yaml:"level"`
+	// Encoding is json, console or combined; empty uses Config.Encoding.
+	// combined only writes HTTPMiddleware access lines.
+	Encoding string `json:"encoding" yaml:"encoding"`
+	// Color colors level names with ANSI escape codes, for terminals
+	Color bool `json:"color" yaml:"color"`
+	// Rotate enables rotation for the files in Paths; nil uses Config.Rotate
+	Rotate *RotateConfig `json:"rotate" yaml:"rotate"`
+
+	// below, if set, excludes entries at or above that level; used by
+	// Config.SplitStderr
+	below *Level
+}
+
+// outputs returns the outputs described by c, with defaults filled in from
+// c's top-level fields. Without Outputs, OutputPaths is a single output that
+// accepts every level, unless SplitStderr divides entries between stdout and
+// stderr.
+func (c Config) outputs() []OutputConfig {
+	if c.SplitStderr {
+		below := WarnLevel
+		return []OutputConfig{
+			{Paths: []string{"stdout"}, Level: TraceLevel, Encoding: c.Encoding, below: &below},
+			{Paths: []string{"stderr"}, Level: WarnLevel, Encoding: c.Encoding},
+		}
+	}
+	if len(c.Outputs) == 0 {
+		return []OutputConfig{{
+			Paths:    c.OutputPaths,
+			Level:    TraceLevel,
+			Encoding: c.Encoding,
+			Rotate:   c.Rotate,
+		}}
+	}
+
+	outputs := make([]OutputConfig, len(c.Outputs))
+	for i, out := range c.Outputs {
+		if out.Encoding == "" {
+			out.Encoding = c.Encoding
+		}
+		if out.Rotate == nil {
+			out.Rotate = c.Rotate
+		}
+		outputs[i] = out
+	}
+	return outputs
+}
+
+// enabler returns the levels written to out
+func (out OutputConfig) enabler() zapcore.LevelEnabler {
+	if out.below == nil {
+		return out.Level
+	}
+	min, below := out.Level, *out.below
+	return zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
+		level := fromZapLevel(lvl)
+		return level >= min && level < below
+	})
+}
+
+// sameDestinations reports whether a and b write to the same files with the
+// same rotation settings, so their open files can be reused
+func sameDestinations(a, b []OutputConfig) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !reflect.DeepEqual(a[i].Paths, b[i].Paths) || !reflect.DeepEqual(a[i].Rotate, b[i].Rotate) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,176 @@
+package golog
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOutputs(t *testing.T) {
+	dir := t.TempDir()
+	console, all, errs := filepath.Join(dir, "console.log"), filepath.Join(dir, "all.log"), filepath.Join(dir, "error.log")
+
+	logger := newFileLogger(t, Config{
+		Level:    DebugLevel,
+		Encoding: "json",
+		Outputs: []OutputConfig{
+			{Paths: []string{console}, Level: DebugLevel, Encoding: "console", Color: true},
+			{Paths: []string{all}, Level: InfoLevel},
+			{Paths: []string{errs}, Level: ErrorLevel},
+		},
+	})
+	child := logger.With(Field("component", "test"))
+
+	child.Debug("debug message")
+	child.Info("info message")
+	child.Error("error message")
+	logger.Sync()
+
+	got := readFile(t, console)
+	if strings.Count(got, " message\t") != 3 || !strings.Contains(got, colorMagenta+"debug"+colorReset) || !strings.Contains(got, `{"component": "test"}`) {
+		t.Errorf("Unexpected console output: %q", got)
+	}
+
+	got = readFile(t, all)
+	if strings.Contains(got, "debug message") || !strings.Contains(got, `"msg":"info message","component":"test"`) || !strings.Contains(got, "error message") {
+		t.Errorf("Unexpected JSON output: %q", got)
+	}
+
+	got = readFile(t, errs)
+	if strings.Count(got, `"msg"`) != 1 || !strings.Contains(got, "error message") {
+		t.Errorf("Expected only the error entry, got %q", got)
+	}
+}
+
+func TestOutputsApplyConfig(t *testing.T) {
+	dir := t.TempDir()
+	first, second := filepath.Join(dir, "first.log"), filepath.Join(dir, "second.log")
+	config := Config{
+		Level:    DebugLevel,
+		Encoding: "json",
+		Outputs: []OutputConfig{
+			{Paths: []string{first}, Level: InfoLevel},
+			{Paths: []string{second}, Level: ErrorLevel},
+		},
+	}
+	logger := newFileLogger(t, config)
+	sinks := logger.config.base.Load().sinks
+
+	config.Outputs[1].Level = DebugLevel
+	if err := logger.ApplyConfig(config); err != nil {
+		t.Fatalf("ApplyConfig failed: %v", err)
+	}
+	if logger.config.base.Load().sinks != sinks {
+		t.Error("Expected files to be reused when only levels change")
+	}
+
+	logger.Debug("debug message")
+	logger.Sync()
+
+	if got := readFile(t, first); got != "" {
+		t.Errorf("Expected nothing in first output, got %q", got)
+	}
+	if got := readFile(t, second); !strings.Contains(got, "debug message") {
+		t.Errorf("Expected debug entry in second output, got %q", got)
+	}
+}
+
+func TestOutputsLoadConfig(t *testing.T) {
+	path := writeConfigFile(t, "log.yaml", `
+encoding: console
+outputs:
+  - paths: [stdout]
+    level: debug
+    color: true
+  - paths: [/var/log/app.log]
+    encoding: json
+    lvl: info
+`)
+
+	config, err := LoadConfig(path)
+	var configErrs ConfigErrors
+	if !errors.As(err, &configErrs) || len(configErrs) != 1 || configErrs[0].Key != "outputs[1].lvl" {
+		t.Fatalf("Expected error for key outputs[1].lvl, got %v", err)
+	}
+
+	if len(config.Outputs) != 2 || config.Outputs[0].Level != DebugLevel || !config.Outputs[0].Color {
+		t.Fatalf("Unexpected outputs %+v", config.Outputs)
+	}
+	outputs := config.outputs()
+	if outputs[0].Encoding != "console" || outputs[1].Encoding != "json" {
+		t.Errorf("Unexpected encodings %q and %q", outputs[0].Encoding, outputs[1].Encoding)
+	}
+}
+
+func TestOutputsValidate(t *testing.T) {
+	config := Config{
+		Level: InfoLevel,
+		Outputs: []OutputConfig{
+			{Paths: []string{"stdout"}, Encoding: "json"},
+			{Level: Level(42), Encoding: "xml", Rotate: &RotateConfig{MaxSize: -1}},
+		},
+	}
+
+	var errs ConfigErrors
+	if !errors.As(config.Validate(), &errs) {
+		t.Fatal("Expected ConfigErrors")
+	}
+	var keys []string
+	for _, e := range errs {
+		keys = append(keys, e.Key)
+	}
+	want := "outputs[1].level,outputs[1].encoding,outputs[1].paths,outputs[1].rotate.maxSize"
+	if got := strings.Join(keys, ","); got != want {
+		t.Errorf("Expected errors for %s, got %s", want, got)
+	}
+}
+
+// redirect points *f at a new file in a temporary directory until the test ends
+func redirect(t *testing.T, f **os.File) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "out")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	orig := *f
+	*f = file
+	t.Cleanup(func() {
+		*f = orig
+		file.Close()
+	})
+	return path
+}
+
+func TestSplitStderr(t *testing.T) {
+	stdout, stderr := redirect(t, &os.Stdout), redirect(t, &os.Stderr)
+
+	config := DefaultConfig()
+	config.Level = DebugLevel
+	config.OutputPaths = []string{filepath.Join(t.TempDir(), "ignored.log")}
+	config.SplitStderr = true
+	logger := newFileLogger(t, config)
+
+	logger.Debug("debug message")
+	logger.Notice("notice message")
+	logger.Warn("warn message")
+	logger.Error("error message")
+	logger.Sync()
+
+	if got := readFile(t, stdout); !strings.Contains(got, "debug message") || !strings.Contains(got, "notice message") ||
+		strings.Contains(got, "warn message") || strings.Contains(got, "error message") {
+		t.Errorf("Unexpected stdout: %q", got)
+	}
+	if got := readFile(t, stderr); strings.Contains(got, "notice message") ||
+		!strings.Contains(got, "warn message") || !strings.Contains(got, "error message") {
+		t.Errorf("Unexpected stderr: %q", got)
+	}
+
+	config.Outputs = []OutputConfig{{Paths: []string{"stdout"}}}
+	var errs ConfigErrors
+	if !errors.As(config.Validate(), &errs) || errs[0].Key != "splitStderr" {
+		t.Errorf("Expected splitStderr error when combined with outputs, got %v", errs)
+	}
+}
@@ -0,0 +1,168 @@
+package golog
+
+import (
+	"context"
+	"net/http"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/muleiwu/gsr"
+	"go.uber.org/zap/zapcore"
+)
+
+// panicMessage is the message of entries logged for recovered panics
+const panicMessage = "panic recovered"
+
+// Recover logs a panic in progress at ErrorLevel, with a "panic" field
+// holding the panic value and the stack of the panicking goroutine, and
+// stops it. It must be deferred directly:
+//
+//	go func() {
+//		defer logger.Recover()
+//		work()
+//	}()
+//
+// Panics are logged even when stack traces are disabled by Config.
+func (l *Logger) Recover() {
+	if r := recover(); r != nil {
+		l.logPanic(context.Background(), ErrorLevel, r)
+	}
+}
+
+// RecoverAt is like Recover but logs at level
+//
+//	defer logger.RecoverAt(golog.CriticalLevel)
+func (l *Logger) RecoverAt(level Level) {
+	if r := recover(); r != nil {
+		l.logPanic(context.Background(), level, r)
+	}
+}
+
+// Go runs fn in a new goroutine, logging a panic in fn with Recover instead
+// of crashing the process
+//
+// Example:
+//
+//	logger.Go(func() {
+//		refreshCache(ctx)
+//	})
+func (l *Logger) Go(fn func()) {
+	go func() {
+		defer l.Recover()
+		fn()
+	}()
+}
+
+// RecoveryMiddleware returns net/http middleware that recovers panics in the
+// handler, logs them at level with the stack and request fields, and
+// responds with 500 Internal Server Error if the handler hasn't written a
+// response yet. Panics with http.ErrAbortHandler, which net/http uses to
+// abort a response silently, are passed on.
+//
+// Requests are logged with the request-scoped logger from FromContext when
+// inside HTTPMiddleware, so entries carry the request ID, and with l plus
+// "method" and "path" fields otherwise. Place it inside HTTPMiddleware so
+// the access line records the 500:
+//
+//	handler := logger.HTTPMiddleware(golog.HTTPConfig{})(
+//		logger.RecoveryMiddleware(golog.ErrorLevel)(mux))
+func (l *Logger) RecoveryMiddleware(level Level) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+			defer func() {
+				p := recover()
+				if p == nil {
+					return
+				}
+				if p == http.ErrAbortHandler {
+					panic(p)
+				}
+
+				ctx := r.Context()
+				logger, ok := ctx.Value(loggerKey{}).(*Logger)
+				if !ok || logger == nil {
+					logger = l.With(Field("method", r.Method), Field("path", r.URL.Path))
+				}
+				logger.logPanic(ctx, level, p)
+
+				if !rec.wroteHeader {
+					http.Error(rec, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				}
+			}()
+			next.ServeHTTP(rec, r)
+		})
+	}
+}
+
+// logPanic logs the recovered panic value p at level, with the caller and
+// stack trace of the panicking frame and the fields from ctx
+func (l *Logger) logPanic(ctx context.Context, level Level, p any) {
+	ce := l.logger.Check(level.toZapLevel(), panicMessage)
+	if ce == nil {
+		return
+	}
+	stack, frame := panicStack()
+	ce.Stack = stack
+	if frame.PC != 0 && ce.Caller.Defined {
+		ce.Caller = zapcore.NewEntryCaller(frame.PC, frame.File, frame.Line, true)
+		ce.Caller.Function = frame.Function
+	}
+	ce.Write(l.contextFields(ctx, []gsr.LoggerField{Field("panic", p)})...)
+}
+
+// panicStack returns the stack of the panicking goroutine, starting at the
+// first frame outside the runtime after runtime.gopanic and formatted like
+// zap's stack traces, and that frame. Without a panic in progress, it returns
+// the whole stack.
+func panicStack() (string, runtime.Frame) {
+	pcs := make([]uintptr, 64)
+	for {
+		n := runtime.Callers(2, pcs)
+		if n < len(pcs) {
+			pcs = pcs[:n]
+			break
+		}
+		pcs = make([]uintptr, 2*len(pcs))
+	}
+
+	var all, fromPanic []runtime.Frame
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		// Runtime panics such as nil dereferences and nil map writes start
+		// in runtime frames below gopanic; the stack starts at the user code
+		if fromPanic != nil && (len(fromPanic) > 0 || !isRuntimeFrame(frame)) {
+			fromPanic = append(fromPanic, frame)
+		}
+		if frame.Function == "runtime.gopanic" {
+			fromPanic = make([]runtime.Frame, 0, len(pcs))
+		}
+		all = append(all, frame)
+		if !more {
+			break
+		}
+	}
+	if len(fromPanic) == 0 {
+		fromPanic = all
+	}
+
+	var b strings.Builder
+	for i, frame := range fromPanic {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(frame.Function)
+		b.WriteString("\n\t")
+		b.WriteString(frame.File)
+		b.WriteByte(':')
+		b.WriteString(strconv.Itoa(frame.Line))
+	}
+	return b.String(), fromPanic[0]
+}
+
+// isRuntimeFrame reports whether frame belongs to the Go runtime
+func isRuntimeFrame(frame runtime.Frame) bool {
+	return strings.HasPrefix(frame.Function, "runtime.") || strings.HasPrefix(frame.Function, "internal/runtime/")
+}
@@ -0,0 +1,186 @@
+package golog
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestRecover(t *testing.T) {
+	logger, logs := newObservedLogger(TraceLevel, zap.AddCaller(), zap.AddCallerSkip(1))
+
+	func() {
+		defer logger.RecoverAt(CriticalLevel)
+		panic("boom")
+	}()
+	func() {
+		defer logger.Recover()
+	}()
+
+	entries := logs.All()
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 entry, got %d", len(entries))
+	}
+	entry := entries[0]
+	if entry.Message != "panic recovered" || fromZapLevel(entry.Level) != CriticalLevel || entry.ContextMap()["panic"] != "boom" {
+		t.Errorf("Unexpected entry %q at %v with %v", entry.Message, fromZapLevel(entry.Level), entry.ContextMap())
+	}
+	if !strings.HasPrefix(entry.Stack, "github.com/muleiwu/golog.TestRecover.func1\n\t") {
+		t.Errorf("Expected the stack to start at the panicking function, got %q", entry.Stack)
+	}
+	if filepath.Base(entry.Caller.File) != "recover_test.go" {
+		t.Errorf("Expected the caller to be the panic site, got %v", entry.Caller)
+	}
+}
+
+func TestRecoverRuntimePanic(t *testing.T) {
+	tests := []struct {
+		name  string
+		panic func()
+	}{
+		{"nil dereference", func() {
+			var p *struct{ n int }
+			_ = p.n
+		}},
+		{"nil map write", func() {
+			var m map[string]int
+			m["key"] = 1
+		}},
+		{"index out of range", func() {
+			s := []int{}
+			_ = s[len(s)]
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, logs := newObservedLogger(TraceLevel, zap.AddCaller(), zap.AddCallerSkip(1))
+			func() {
+				defer logger.Recover()
+				tt.panic()
+			}()
+
+			entries := logs.All()
+			if len(entries) != 1 {
+				t.Fatalf("Expected 1 entry, got %d", len(entries))
+			}
+			entry := entries[0]
+			if !strings.HasPrefix(entry.Stack, "github.com/muleiwu/golog.TestRecoverRuntimePanic.func") {
+				t.Errorf("Expected the stack to start at the panicking function, got %q", entry.Stack)
+			}
+			if filepath.Base(entry.Caller.File) != "recover_test.go" {
+				t.Errorf("Expected the caller to be the panic site, got %v", entry.Caller)
+			}
+		})
+	}
+}
+
+func TestRecoverError(t *testing.T) {
+	logger, logs := newObservedLogger(TraceLevel, zap.AddCaller(), zap.AddCallerSkip(1))
+	err := errors.New("nil map")
+
+	func() {
+		defer logger.Recover()
+		panic(err)
+	}()
+
+	entries := logs.All()
+	if len(entries) != 1 || fromZapLevel(entries[0].Level) != ErrorLevel || entries[0].ContextMap()["panic"] != "nil map" {
+		t.Errorf("Expected an error entry for the panic, got %v", entries)
+	}
+}
+
+func TestGo(t *testing.T) {
+	logger, logs := newObservedLogger(TraceLevel, zap.AddCaller(), zap.AddCallerSkip(1))
+
+	logger.Go(func() {
+		panic("in goroutine")
+	})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for logs.Len() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	entries := logs.All()
+	if len(entries) != 1 || entries[0].ContextMap()["panic"] != "in goroutine" {
+		t.Fatalf("Expected the goroutine's panic to be logged, got %v", entries)
+	}
+	if !strings.Contains(entries[0].Stack, "TestGo.func1") {
+		t.Errorf("Expected the stack to include the goroutine's function, got %q", entries[0].Stack)
+	}
+}
+
+func TestRecoveryMiddleware(t *testing.T) {
+	logger, logs := newObservedLogger(TraceLevel, zap.AddCaller(), zap.AddCallerSkip(1))
+	handler := logger.HTTPMiddleware(HTTPConfig{})(logger.RecoveryMiddleware(ErrorLevel)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic("handler failed")
+		})))
+
+	req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	req.Header.Set("X-Request-ID", "req-7")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("Expected 500, got %d", rec.Code)
+	}
+	entries := logs.All()
+	if len(entries) != 2 {
+		t.Fatalf("Expected a panic entry and an access line, got %d entries", len(entries))
+	}
+	panicFields := entries[0].ContextMap()
+	if entries[0].Message != "panic recovered" || panicFields["request_id"] != "req-7" || panicFields["panic"] != "handler failed" {
+		t.Errorf("Expected the panic with request fields, got %q with %v", entries[0].Message, panicFields)
+	}
+	if entries[0].Stack == "" {
+		t.Error("Expected a stack trace")
+	}
+	if status := entries[1].ContextMap()["status"]; status != int64(500) {
+		t.Errorf("Expected the access line to record 500, got %v", status)
+	}
+}
+
+func TestRecoveryMiddlewareStandalone(t *testing.T) {
+	logger, logs := newObservedLogger(TraceLevel, zap.AddCaller(), zap.AddCallerSkip(1))
+	handler := logger.RecoveryMiddleware(CriticalLevel)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		panic("after writing")
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/jobs", nil))
+
+	if rec.Code != http.StatusAccepted {
+		t.Errorf("Expected the written status to be kept, got %d", rec.Code)
+	}
+	entries := logs.All()
+	if len(entries) != 1 || fromZapLevel(entries[0].Level) != CriticalLevel {
+		t.Fatalf("Expected one critical entry, got %v", entries)
+	}
+	if fields := entries[0].ContextMap(); fields["method"] != "POST" || fields["path"] != "/jobs" {
+		t.Errorf("Expected request fields, got %v", fields)
+	}
+}
+
+func TestRecoveryMiddlewareAbort(t *testing.T) {
+	logger, logs := newObservedLogger(TraceLevel, zap.AddCaller(), zap.AddCallerSkip(1))
+	handler := logger.RecoveryMiddleware(ErrorLevel)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+
+	defer func() {
+		if p := recover(); p != http.ErrAbortHandler {
+			t.Errorf("Expected ErrAbortHandler to be re-panicked, got %v", p)
+		}
+		if logs.Len() != 0 {
+			t.Errorf("Expected aborted requests not to be logged, got %d entries", logs.Len())
+		}
+	}()
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
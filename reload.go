@@ -0,0 +1,366 @@
+package golog
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// defaultWatchInterval is how often NewWatchedLogger checks its config file
+// when no interval is given
+const defaultWatchInterval = time.Second
+
+// errNoConfig is returned by ApplyConfig and Reload on loggers that were not
+// built from a Config
+var errNoConfig = errors.New("golog: logger was not created from a Config")
+
+// errClosed is returned by ApplyConfig and Reload on closed loggers
+var errClosed = errors.New("golog: logger is closed")
+
+// configState holds everything NewLoggerWithConfig built from a Config, so
+// that ApplyConfig can replace it while the logger and its children are in
+// use. It is shared by a logger and all of its children.
+type configState struct {
+	// mu serialises apply and Close
+	mu sync.Mutex
+	// closed is set by Close, after which apply opens nothing new
+	closed bool
+	// path is the file watched by NewWatchedLogger, if any
+	path string
+
+	// base holds the cores and files built from the current config
+	base atomic.Pointer[generation]
+	// swapMu is held for reading while an entry is written and for writing
+	// while base is replaced, so files are never closed mid-write
+	swapMu sync.RWMutex
+	// stack holds when stack traces are captured and how they are formatted
+	stack atomic.Pointer[stackConfig]
+
+	levels      *levelSet
+	verbosity   *verbosity
+	errorOutput zapcore.WriteSyncer
+}
+
+// generation is what was built from one version of the config
+type generation struct {
+	n    uint64
+	core zapcore.Core
+	// outputs, sinks and writers are reused by the next generation if its
+	// outputs write to the same places
+	outputs []OutputConfig
+	sinks   *sinkSet
+	writers []zapcore.WriteSyncer
+}
+
+// derivedCore is a generation's core with a child's fields applied
+type derivedCore struct {
+	n    uint64
+	core zapcore.Core
+}
+
+// newConfigState creates a configState logging to errorOutput and applies config
+func newConfigState(config Config, errorOutput zapcore.WriteSyncer) (*configState, error) {
+	s := &configState{
+		levels:      newLevelSet(config.Level),
+		verbosity:   &verbosity{},
+		errorOutput: errorOutput,
+	}
+	if err := s.apply(config); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// apply builds everything config needs and only then swaps it in, so an
+// invalid config leaves the current one in place. CallerSkip and
+// ErrorOutputPaths are fixed when the logger is created and are ignored.
+func (s *configState) apply(config Config) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return errClosed
+	}
+
+	outputs := config.outputs()
+	encoders := make([]zapcore.Encoder, len(outputs))
+	for i, out := range outputs {
+		encoder, err := newOutputEncoder(config, out)
+		if err != nil {
+			return err
+		}
+		encoders[i] = encoder
+	}
+	filters, err := parseVModule(config.VModule)
+	if err != nil {
+		return err
+	}
+
+	prev := s.base.Load()
+	next := &generation{outputs: outputs}
+	if prev != nil {
+		next.n = prev.n + 1
+	}
+	if prev != nil && sameDestinations(prev.outputs, outputs) {
+		next.sinks, next.writers = prev.sinks, prev.writers
+	} else {
+		next.sinks = &sinkSet{}
+		for _, out := range outputs {
+			ws, err := next.sinks.open(out.Paths, out.Rotate)
+			if err != nil {
+				next.sinks.Close()
+				return err
+			}
+			next.writers = append(next.writers, ws)
+		}
+	}
+
+	cores := make([]zapcore.Core, len(outputs))
+	for i, out := range outputs {
+		cores[i] = newIOCore(encoders[i], next.writers[i], out.enabler())
+	}
+	next.core = zapcore.NewTee(cores...)
+	if config.Sampling != nil {
+		next.core = newSamplingCore(next.core, *config.Sampling)
+	}
+	if fields := config.initialFields(); len(fields) > 0 {
+		next.core = next.core.With(fields)
+	}
+
+	s.swapMu.Lock()
+	s.base.Store(next)
+	s.swapMu.Unlock()
+	if prev != nil && prev.sinks != next.sinks {
+		prev.sinks.Close()
+	}
+
+	s.levels.setGlobal(config.Level)
+	s.levels.replace(config.NamedLevels)
+	s.verbosity.level.Store(int32(config.Verbosity))
+	s.verbosity.setFilters(filters)
+
+	s.stack.Store(newStackConfig(config))
+	return nil
+}
+
+// Reopen reopens the files of the current outputs
+func (s *configState) Reopen() error {
+	s.swapMu.RLock()
+	defer s.swapMu.RUnlock()
+	return s.base.Load().sinks.Reopen()
+}
+
+// Close closes the files of the current outputs
+func (s *configState) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	s.swapMu.Lock()
+	defer s.swapMu.Unlock()
+	return s.base.Load().sinks.Close()
+}
+
+// stacktraceEnabled reports whether entries at lvl capture a stack trace
+func (s *configState) stacktraceEnabled(lvl zapcore.Level) bool {
+	return s.stack.Load().enabled(lvl)
+}
+
+// reload loads the watched file and applies it. An empty file is rejected
+// rather than loaded as DefaultConfig, since editors that truncate a file
+// before rewriting it would otherwise switch the logger to stdout.
+func (s *configState) reload() error {
+	if s.path == "" {
+		return errors.New("golog: logger has no config file")
+	}
+	config, err := loadConfig(s.path)
+	if err != nil {
+		return err
+	}
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	return s.apply(config)
+}
+
+// watch polls the config file every interval and reloads it when its size
+// or modification time changes, until the returned function is called. The
+// function waits for a reload in progress to finish. Failed reloads are
+// written to s.errorOutput and the current config is kept.
+func (s *configState) watch(interval time.Duration) func() {
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+
+	var last os.FileInfo
+	if fi, err := os.Stat(s.path); err == nil {
+		last = fi
+	}
+
+	ticker := time.NewTicker(interval)
+	done, exited := make(chan struct{}), make(chan struct{})
+
+	go func() {
+		defer close(exited)
+		for {
+			select {
+			case <-ticker.C:
+				fi, err := os.Stat(s.path)
+				if err != nil || (last != nil && fi.ModTime().Equal(last.ModTime()) && fi.Size() == last.Size()) {
+					continue
+				}
+				last = fi
+				if err := s.reload(); err != nil {
+					fmt.Fprintf(s.errorOutput, "golog: config reload failed, keeping previous config: %v\n", err)
+					s.errorOutput.Sync()
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			ticker.Stop()
+			close(done)
+			<-exited
+		})
+	}
+}
+
+// reloadCore logs through the cores built from the most recent config.
+// Children created with With keep their fields and re-apply them to the new
+// cores after a reload, so they never log through a stale configuration.
+//
+// Check only consults the level; the current cores are checked again and
+// written to in Write, under configState.swapMu, so that a reload can't close
+// an output between the two.
+type reloadCore struct {
+	state  *configState
+	fields []zapcore.Field
+	// derived caches the current generation's core with fields applied
+	derived atomic.Pointer[derivedCore]
+}
+
+// current returns the core for the current generation
+func (c *reloadCore) current() zapcore.Core {
+	base := c.state.base.Load()
+	if len(c.fields) == 0 {
+		return base.core
+	}
+	if d := c.derived.Load(); d != nil && d.n == base.n {
+		return d.core
+	}
+	d := &derivedCore{n: base.n, core: base.core.With(c.fields)}
+	c.derived.Store(d)
+	return d.core
+}
+
+// Enabled reports whether any current output allows lvl
+func (c *reloadCore) Enabled(lvl zapcore.Level) bool {
+	return c.state.base.Load().core.Enabled(lvl)
+}
+
+// With returns a child core that adds fields to every entry
+func (c *reloadCore) With(fields []zapcore.Field) zapcore.Core {
+	all := make([]zapcore.Field, 0, len(c.fields)+len(fields))
+	all = append(append(all, c.fields...), fields...)
+	return &reloadCore{state: c.state, fields: all}
+}
+
+// Check adds c to ce if any current output allows the entry's level
+func (c *reloadCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write writes the entry to every current output that accepts it, with its
+// stack trace formatted as configured. Write failures are reported on the
+// error output.
+func (c *reloadCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	c.state.swapMu.RLock()
+	defer c.state.swapMu.RUnlock()
+
+	ent.Stack = c.state.stack.Load().format(ent.Stack)
+	if ce := c.current().Check(ent, nil); ce != nil {
+		ce.ErrorOutput = c.state.errorOutput
+		ce.Write(fields...)
+	}
+	return nil
+}
+
+// Sync flushes the current outputs
+func (c *reloadCore) Sync() error {
+	c.state.swapMu.RLock()
+	defer c.state.swapMu.RUnlock()
+	return c.current().Sync()
+}
+
+// NewWatchedLogger creates a logger from the config file at path (see
+// LoadConfig) and checks the file for changes every interval, one second if
+// interval is zero. Changes are applied as with ApplyConfig. An edit that
+// fails to load or apply is reported on the error output and the previous
+// configuration is kept. Close stops watching.
+//
+// Example:
+//
+//	logger, err := golog.NewWatchedLogger("/etc/app/log.yaml", 0)
+//	if err != nil {
+//		panic(err)
+//	}
+//	defer logger.Close()
+func NewWatchedLogger(path string, interval time.Duration) (*Logger, error) {
+	config, err := LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	logger, err := NewLoggerWithConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	logger.config.path = path
+	logger.sinks.addStop(logger.config.watch(interval))
+	return logger, nil
+}
+
+// ApplyConfig reconfigures a logger created by NewLoggerWithConfig or
+// NewWatchedLogger while it is in use. Level, NamedLevels, Verbosity,
+// VModule, Encoding, Encoder, DisableCallerTrim, the Stacktrace settings,
+// InitialFields, Metadata, Kubernetes, OutputPaths, Outputs, SplitStderr,
+// Sampling and Rotate take effect for the logger and every child created
+// from it, including fields added with With. Development only changes the
+// default encoder settings and stack trace level; whether DPanic panics is
+// fixed when the logger is created, as are CallerSkip and ErrorOutputPaths.
+// Files are only reopened when the paths or rotation settings of the outputs
+// change.
+//
+// If config fails Validate or can't be applied, or the logger was closed,
+// ApplyConfig returns an error and leaves the logger unchanged. Levels set with SetLevel or SetNamedLevel
+// are replaced by those in config.
+func (l *Logger) ApplyConfig(config Config) error {
+	if l.config == nil {
+		return errNoConfig
+	}
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	return l.config.apply(config)
+}
+
+// Reload reloads the config file of a logger created by NewWatchedLogger
+// immediately, without waiting for the next check. Unlike automatic
+// reloads, errors are returned rather than written to the error output.
+func (l *Logger) Reload() error {
+	if l.config == nil {
+		return errNoConfig
+	}
+	return l.config.reload()
+}
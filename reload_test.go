@@ -0,0 +1,218 @@
+package golog
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestApplyConfig(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "app.log")
+	config := Config{Level: InfoLevel, Encoding: "json", OutputPaths: []string{filename}}
+	logger := newFileLogger(t, config)
+	child := logger.With(Field("request_id", "abc"))
+
+	child.Debug("hidden before reload")
+
+	config.Level = DebugLevel
+	config.Encoding = "console"
+	if err := logger.ApplyConfig(config); err != nil {
+		t.Fatalf("ApplyConfig failed: %v", err)
+	}
+	if got := logger.Level(); got != DebugLevel {
+		t.Errorf("Expected level %v after ApplyConfig, got %v", DebugLevel, got)
+	}
+
+	child.Debug("shown after reload")
+	logger.Sync()
+
+	got := readFile(t, filename)
+	if strings.Contains(got, "hidden before reload") {
+		t.Errorf("Debug entry logged before reload: %q", got)
+	}
+	if !strings.Contains(got, "shown after reload") || !strings.Contains(got, `{"request_id": "abc"}`) {
+		t.Errorf("Expected console entry with child fields after reload, got %q", got)
+	}
+}
+
+func TestApplyConfigOutputs(t *testing.T) {
+	dir := t.TempDir()
+	first, second := filepath.Join(dir, "first.log"), filepath.Join(dir, "second.log")
+	config := Config{Level: InfoLevel, Encoding: "json", OutputPaths: []string{first}}
+	logger := newFileLogger(t, config)
+	child := logger.Named("worker")
+
+	child.Info("to first")
+	config.OutputPaths = []string{second}
+	if err := logger.ApplyConfig(config); err != nil {
+		t.Fatalf("ApplyConfig failed: %v", err)
+	}
+	child.Info("to second")
+	logger.Sync()
+
+	if got := readFile(t, first); !strings.Contains(got, "to first") || strings.Contains(got, "to second") {
+		t.Errorf("Unexpected content in first output: %q", got)
+	}
+	if got := readFile(t, second); !strings.Contains(got, "to second") {
+		t.Errorf("Expected second output to receive logs, got %q", got)
+	}
+}
+
+func TestApplyConfigInvalid(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "app.log")
+	config := Config{Level: InfoLevel, Encoding: "json", OutputPaths: []string{filename}}
+	logger := newFileLogger(t, config)
+
+	invalid := []Config{
+		{Level: DebugLevel, Encoding: "xml", OutputPaths: []string{filename}},
+		{Level: DebugLevel, Encoding: "json", OutputPaths: []string{filename}, VModule: "db"},
+		{Level: DebugLevel, Encoding: "json", OutputPaths: []string{filepath.Join(filename, "missing", "app.log")}},
+	}
+	for _, c := range invalid {
+		if err := logger.ApplyConfig(c); err == nil {
+			t.Errorf("Expected error applying %+v", c)
+		}
+	}
+
+	logger.Debug("still hidden")
+	logger.Info("still logged")
+	logger.Sync()
+
+	got := readFile(t, filename)
+	if strings.Contains(got, "still hidden") || !strings.Contains(got, `"msg":"still logged"`) {
+		t.Errorf("Expected previous config to be kept, got %q", got)
+	}
+}
+
+func TestApplyConfigAfterClose(t *testing.T) {
+	dir := t.TempDir()
+	config := Config{Level: InfoLevel, Encoding: "json", OutputPaths: []string{filepath.Join(dir, "app.log")}}
+	logger := newFileLogger(t, config)
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	reopened := filepath.Join(dir, "new.log")
+	config.OutputPaths = []string{reopened}
+	if err := logger.ApplyConfig(config); !errors.Is(err, errClosed) {
+		t.Errorf("Expected errClosed from ApplyConfig after Close, got %v", err)
+	}
+	if _, err := os.Stat(reopened); !os.IsNotExist(err) {
+		t.Errorf("Expected no output to be opened after Close, got %v", err)
+	}
+}
+
+func TestApplyConfigWithoutConfig(t *testing.T) {
+	if err := NewLogger().ApplyConfig(DefaultConfig()); err == nil {
+		t.Error("Expected error for logger not created from a Config")
+	}
+	if err := NewLogger().Reload(); err == nil {
+		t.Error("Expected error for logger without a config file")
+	}
+}
+
+func TestWatchedLogger(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "app.log")
+	errOutput := filepath.Join(dir, "error.log")
+	path := filepath.Join(dir, "log.yaml")
+
+	writeConfig := func(level string, modTime time.Time) {
+		t.Helper()
+		data := "level: " + level + "\noutputPaths: [" + output + "]\nerrorOutputPaths: [" + errOutput + "]\n"
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatalf("Chtimes failed: %v", err)
+		}
+	}
+	waitFor := func(cond func() bool) bool {
+		for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+			if cond() {
+				return true
+			}
+		}
+		return false
+	}
+
+	start := time.Now().Add(-time.Hour)
+	writeConfig("info", start)
+
+	logger, err := NewWatchedLogger(path, 5*time.Millisecond)
+	if err != nil {
+		t.Fatalf("NewWatchedLogger failed: %v", err)
+	}
+	defer logger.Close()
+
+	writeConfig("debug", start.Add(time.Minute))
+	if !waitFor(func() bool { return logger.Level() == DebugLevel }) {
+		t.Fatalf("Expected level %v after editing the file, got %v", DebugLevel, logger.Level())
+	}
+
+	writeConfig("loud", start.Add(2*time.Minute))
+	if !waitFor(func() bool {
+		data, _ := os.ReadFile(errOutput)
+		return strings.Contains(string(data), "config reload failed")
+	}) {
+		t.Fatal("Expected invalid edit to be reported on the error output")
+	}
+	if got := logger.Level(); got != DebugLevel {
+		t.Errorf("Expected previous level %v to be kept, got %v", DebugLevel, got)
+	}
+
+	writeConfig("warn", start.Add(3*time.Minute))
+	if err := logger.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if got := logger.Level(); got != WarnLevel {
+		t.Errorf("Expected level %v after Reload, got %v", WarnLevel, got)
+	}
+}
+
+func TestWatchedLoggerTruncated(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "app.log")
+	errOutput := filepath.Join(dir, "error.log")
+	path := filepath.Join(dir, "log.yaml")
+
+	data := "outputPaths: [" + output + "]\nerrorOutputPaths: [" + errOutput + "]\n"
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	logger, err := NewWatchedLogger(path, 5*time.Millisecond)
+	if err != nil {
+		t.Fatalf("NewWatchedLogger failed: %v", err)
+	}
+	defer logger.Close()
+
+	// Editors that save in place truncate the file before rewriting it
+	for _, content := range []string{" \n  \n", ""} {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+		if err := logger.Reload(); err == nil {
+			t.Errorf("Expected Reload to reject %q", content)
+		}
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		data, _ := os.ReadFile(errOutput)
+		if strings.Contains(string(data), "config file is empty") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the empty file to be reported on the error output, got %q", data)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	logger.Info("still to app.log")
+	logger.Sync()
+	if got := readFile(t, output); !strings.Contains(got, "still to app.log") {
+		t.Errorf("Expected the previous outputs to be kept, got %q", got)
+	}
+}
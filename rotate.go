@@ -0,0 +1,486 @@
+package golog
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// rotateScheme is the OutputPaths scheme that selects a RotatingWriter,
+	// e.g. rotate:///var/log/app.log?max_size=100&max_backups=5
+	rotateScheme = "rotate"
+	// compressSuffix is appended to backups compressed with gzip
+	compressSuffix = ".gz"
+	// backupTimeFormat is the timestamp embedded in rotated file names
+	backupTimeFormat = "2006-01-02T15-04-05.000"
+	// defaultMaxSize is the rotation threshold in megabytes when MaxSize is 0
+	defaultMaxSize = 100
+	megabyte       = 1024 * 1024
+)
+
+// currentTime is replaced in tests to control backup file names
+var currentTime = time.Now
+
+// RotateConfig configures size- and age-based rotation of log files
+type RotateConfig struct {
+	// MaxSize is the maximum size in megabytes of a log file before it is rotated.
+	// Default is 100 megabytes.
+	MaxSize int `json:"maxSize" yaml:"maxSize"`
+	// MaxAge is the maximum number of days to retain rotated files, based on the
+	// timestamp encoded in their name. 0 disables age-based removal.
+	MaxAge int `json:"maxAge" yaml:"maxAge"`
+	// MaxBackups is the maximum number of rotated files to retain.
+	// 0 retains all of them (subject to MaxAge).
+	MaxBackups int `json:"maxBackups" yaml:"maxBackups"`
+	// LocalTime names rotated files using local time instead of UTC
+	LocalTime bool `json:"localTime" yaml:"localTime"`
+	// Compress gzips rotated files in the background
+	Compress bool `json:"compress" yaml:"compress"`
+	// MaxTotalSize is the maximum size in megabytes of the active file and all
+	// rotated files together. The oldest backups are removed once it is exceeded.
+	// 0 disables the limit.
+	MaxTotalSize int `json:"maxTotalSize" yaml:"maxTotalSize"`
+}
+
+// RotatingWriter is a zapcore.WriteSyncer that writes to a file and rotates it
+// once it grows beyond RotateConfig.MaxSize.
+//
+// Rotated files are renamed to name-<timestamp>.ext next to the original file,
+// e.g. app.log becomes app-2024-02-10T15-04-05.000.log. Compression and
+// removal of old backups happen in a background goroutine after each rotation.
+type RotatingWriter struct {
+	filename string
+	config   RotateConfig
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+	// closed is set by Close; file is also nil after a failed rotation,
+	// which the next Write recovers from by opening the file again
+	closed bool
+
+	// millCh wakes the background goroutine that compresses and removes backups
+	millCh   chan struct{}
+	millDone chan struct{}
+}
+
+// NewRotatingWriter opens (or creates) filename for appending and returns a
+// writer that rotates it according to config
+func NewRotatingWriter(filename string, config RotateConfig) (*RotatingWriter, error) {
+	w := &RotatingWriter{
+		filename: filename,
+		config:   config,
+	}
+	if err := w.openExisting(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Filename returns the path of the active log file
+func (w *RotatingWriter) Filename() string {
+	return w.filename
+}
+
+// Write writes p to the active file, rotating first if p would push the file
+// past MaxSize
+func (w *RotatingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+	if w.file == nil {
+		if err := w.openExisting(); err != nil {
+			return 0, err
+		}
+	}
+
+	// A single entry larger than MaxSize is still written, into a fresh file
+	if w.size > 0 && w.size+int64(len(p)) > w.maxSize() {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Sync commits the active file's contents to stable storage
+func (w *RotatingWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+	return w.file.Sync()
+}
+
+// Close closes the active file and waits for any pending compression or
+// cleanup of rotated files to finish. Later writes fail with os.ErrClosed.
+func (w *RotatingWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.closed = true
+	w.stopMill()
+	return w.closeFile()
+}
+
+// Reopen closes the active file and opens filename again without rotating,
+// e.g. after an external tool moved the file away
+func (w *RotatingWriter) Reopen() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return os.ErrClosed
+	}
+	if err := w.closeFile(); err != nil {
+		return err
+	}
+	return w.openExisting()
+}
+
+// Rotate closes the active file, renames it to a timestamped backup and opens
+// a new file in its place
+func (w *RotatingWriter) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return os.ErrClosed
+	}
+	return w.rotate()
+}
+
+// maxSize returns the rotation threshold in bytes
+func (w *RotatingWriter) maxSize() int64 {
+	if w.config.MaxSize <= 0 {
+		return defaultMaxSize * megabyte
+	}
+	return int64(w.config.MaxSize) * megabyte
+}
+
+// openExisting opens the log file for appending, creating it and its
+// directory if needed
+func (w *RotatingWriter) openExisting() error {
+	if err := os.MkdirAll(filepath.Dir(w.filename), 0o755); err != nil {
+		return fmt.Errorf("golog: can't create log directory: %w", err)
+	}
+
+	file, err := os.OpenFile(w.filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("golog: can't open log file: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("golog: can't stat log file: %w", err)
+	}
+
+	w.file = file
+	w.size = info.Size()
+	return nil
+}
+
+// closeFile closes the active file if it is open
+func (w *RotatingWriter) closeFile() error {
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	w.size = 0
+	return err
+}
+
+// rotate performs the rotation; the caller must hold w.mu
+func (w *RotatingWriter) rotate() error {
+	if err := w.closeFile(); err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(w.filename); err == nil {
+		if err := os.Rename(w.filename, w.backupName(currentTime())); err != nil {
+			return fmt.Errorf("golog: can't rename log file: %w", err)
+		}
+	}
+
+	if err := w.openExisting(); err != nil {
+		return err
+	}
+
+	w.startMill()
+	return nil
+}
+
+// backupName returns the name of the backup file for a rotation at t
+func (w *RotatingWriter) backupName(t time.Time) string {
+	dir, prefix, ext := w.nameParts()
+	if !w.config.LocalTime {
+		t = t.UTC()
+	}
+	return filepath.Join(dir, prefix+t.Format(backupTimeFormat)+ext)
+}
+
+// nameParts splits the log file name into the directory, the prefix shared
+// by its backups and the extension
+func (w *RotatingWriter) nameParts() (dir, prefix, ext string) {
+	dir = filepath.Dir(w.filename)
+	base := filepath.Base(w.filename)
+	ext = filepath.Ext(base)
+	prefix = strings.TrimSuffix(base, ext) + "-"
+	return dir, prefix, ext
+}
+
+// backupFile is a rotated log file found on disk
+type backupFile struct {
+	path       string
+	timestamp  time.Time
+	size       int64
+	compressed bool
+}
+
+// backups returns the rotated files belonging to this writer, newest first
+func (w *RotatingWriter) backups() ([]backupFile, error) {
+	dir, prefix, ext := w.nameParts()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []backupFile
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		compressed := strings.HasSuffix(name, ext+compressSuffix)
+		trimmed := strings.TrimSuffix(name, compressSuffix)
+		if !strings.HasPrefix(trimmed, prefix) || !strings.HasSuffix(trimmed, ext) {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimPrefix(trimmed, prefix), ext)
+		t, err := time.ParseInLocation(backupTimeFormat, stamp, w.location())
+		if err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, backupFile{
+			path:       filepath.Join(dir, name),
+			timestamp:  t,
+			size:       info.Size(),
+			compressed: compressed,
+		})
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].timestamp.After(files[j].timestamp)
+	})
+	return files, nil
+}
+
+// location returns the time zone used in backup file names
+func (w *RotatingWriter) location() *time.Location {
+	if w.config.LocalTime {
+		return time.Local
+	}
+	return time.UTC
+}
+
+// startMill wakes the background goroutine, starting it if needed; the
+// caller must hold w.mu
+func (w *RotatingWriter) startMill() {
+	if !w.config.Compress && w.config.MaxBackups <= 0 && w.config.MaxAge <= 0 && w.config.MaxTotalSize <= 0 {
+		return
+	}
+
+	if w.millCh == nil {
+		w.millCh = make(chan struct{}, 1)
+		w.millDone = make(chan struct{})
+		go w.millLoop(w.millCh, w.millDone)
+	}
+
+	select {
+	case w.millCh <- struct{}{}:
+	default:
+		// A run is already pending and will see the new backup
+	}
+}
+
+// stopMill stops the background goroutine after its pending run; the caller
+// must hold w.mu
+func (w *RotatingWriter) stopMill() {
+	if w.millCh == nil {
+		return
+	}
+	close(w.millCh)
+	<-w.millDone
+	w.millCh = nil
+	w.millDone = nil
+}
+
+// millLoop runs mill each time it is signalled until millCh is closed
+func (w *RotatingWriter) millLoop(millCh <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	for range millCh {
+		w.mill()
+	}
+}
+
+// mill compresses rotated files and removes those beyond MaxBackups, older
+// than MaxAge or exceeding MaxTotalSize. Failures are ignored: a leftover
+// backup must never stop logging.
+func (w *RotatingWriter) mill() {
+	files, err := w.backups()
+	if err != nil {
+		return
+	}
+
+	var remaining []backupFile
+	cutoff := currentTime().Add(-time.Duration(w.config.MaxAge) * 24 * time.Hour)
+	for i, f := range files {
+		expired := w.config.MaxBackups > 0 && i >= w.config.MaxBackups
+		if w.config.MaxAge > 0 && f.timestamp.Before(cutoff) {
+			expired = true
+		}
+		if expired {
+			os.Remove(f.path)
+			continue
+		}
+		remaining = append(remaining, f)
+	}
+
+	if w.config.Compress {
+		for i, f := range remaining {
+			if f.compressed {
+				continue
+			}
+			if compressed, err := compressFile(f.path); err == nil {
+				remaining[i] = compressed
+			}
+		}
+	}
+
+	if w.config.MaxTotalSize > 0 {
+		var total int64
+		if info, err := os.Stat(w.filename); err == nil {
+			total = info.Size()
+		}
+		budget := int64(w.config.MaxTotalSize) * megabyte
+		for _, f := range remaining {
+			total += f.size
+			if total > budget {
+				os.Remove(f.path)
+			}
+		}
+	}
+}
+
+// compressFile gzips path into path.gz and removes the original
+func compressFile(path string) (backupFile, error) {
+	dstPath := path + compressSuffix
+	if err := gzipFile(path, dstPath); err != nil {
+		os.Remove(dstPath)
+		return backupFile{}, err
+	}
+
+	info, err := os.Stat(dstPath)
+	if err != nil {
+		return backupFile{}, err
+	}
+	if err := os.Remove(path); err != nil {
+		return backupFile{}, err
+	}
+	return backupFile{path: dstPath, size: info.Size(), compressed: true}, nil
+}
+
+// gzipFile writes a gzip-compressed copy of src to dst
+func gzipFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, info.Mode())
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(out)
+	_, err = io.Copy(gz, in)
+	if closeErr := gz.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// parseRotateURL parses a rotate:// output path into a file name and the
+// rotation settings for it. Query parameters max_size, max_age, max_backups,
+// local_time, compress and max_total_size override the values in base.
+func parseRotateURL(rawURL string, base RotateConfig) (string, RotateConfig, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", base, fmt.Errorf("golog: can't parse output path %q: %w", rawURL, err)
+	}
+
+	// Support both rotate:///abs/path.log and rotate://relative/path.log
+	filename := u.Host + u.Path
+	if filename == "" {
+		return "", base, fmt.Errorf("golog: output path %q has no file name", rawURL)
+	}
+
+	config := base
+	for key, values := range u.Query() {
+		value := values[len(values)-1]
+		switch key {
+		case "max_size":
+			config.MaxSize, err = strconv.Atoi(value)
+		case "max_age":
+			config.MaxAge, err = strconv.Atoi(value)
+		case "max_backups":
+			config.MaxBackups, err = strconv.Atoi(value)
+		case "local_time":
+			config.LocalTime, err = strconv.ParseBool(value)
+		case "compress":
+			config.Compress, err = strconv.ParseBool(value)
+		case "max_total_size":
+			config.MaxTotalSize, err = strconv.Atoi(value)
+		default:
+			return "", base, fmt.Errorf("golog: unknown rotate option %q in %q", key, rawURL)
+		}
+		if err != nil {
+			return "", base, fmt.Errorf("golog: invalid rotate option %s=%q: %w", key, value, err)
+		}
+	}
+
+	return filename, config, nil
+}
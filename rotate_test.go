@@ -0,0 +1,288 @@
+package golog
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeClock makes currentTime return increasing timestamps for the duration of a test
+func fakeClock(t *testing.T, start time.Time) {
+	t.Helper()
+	now := start
+	currentTime = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+	t.Cleanup(func() { currentTime = time.Now })
+}
+
+func TestRotatingWriterRotatesOnSize(t *testing.T) {
+	fakeClock(t, time.Date(2024, 2, 10, 15, 4, 5, 0, time.UTC))
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+
+	w, err := NewRotatingWriter(filename, RotateConfig{MaxSize: 1})
+	if err != nil {
+		t.Fatalf("NewRotatingWriter failed: %v", err)
+	}
+	defer w.Close()
+
+	chunk := []byte(strings.Repeat("x", megabyte/2))
+	for i := 0; i < 3; i++ {
+		if _, err := w.Write(chunk); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+
+	backups, err := w.backups()
+	if err != nil {
+		t.Fatalf("backups failed: %v", err)
+	}
+	if len(backups) != 1 {
+		t.Fatalf("Expected 1 backup, got %d", len(backups))
+	}
+	if !strings.HasPrefix(filepath.Base(backups[0].path), "app-2024-02-10T15-04-") {
+		t.Errorf("Unexpected backup name %q", backups[0].path)
+	}
+
+	info, err := os.Stat(filename)
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	if info.Size() != int64(len(chunk)) {
+		t.Errorf("Expected active file size %d, got %d", len(chunk), info.Size())
+	}
+}
+
+func TestRotatingWriterMaxBackups(t *testing.T) {
+	fakeClock(t, time.Date(2024, 2, 10, 0, 0, 0, 0, time.UTC))
+	dir := t.TempDir()
+
+	w, err := NewRotatingWriter(filepath.Join(dir, "app.log"), RotateConfig{MaxBackups: 2})
+	if err != nil {
+		t.Fatalf("NewRotatingWriter failed: %v", err)
+	}
+	defer w.Close()
+
+	for i := 0; i < 5; i++ {
+		w.Write([]byte("line\n"))
+		if err := w.Rotate(); err != nil {
+			t.Fatalf("Rotate failed: %v", err)
+		}
+	}
+	// Close waits for the background cleanup
+	w.Close()
+
+	backups, err := w.backups()
+	if err != nil {
+		t.Fatalf("backups failed: %v", err)
+	}
+	if len(backups) != 2 {
+		t.Errorf("Expected 2 backups, got %d", len(backups))
+	}
+}
+
+func TestRotatingWriterMaxAge(t *testing.T) {
+	fakeClock(t, time.Date(2024, 2, 10, 0, 0, 0, 0, time.UTC))
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+
+	stale := filepath.Join(dir, "app-2024-01-01T00-00-00.000.log")
+	if err := os.WriteFile(stale, []byte("old\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	w, err := NewRotatingWriter(filename, RotateConfig{MaxAge: 7})
+	if err != nil {
+		t.Fatalf("NewRotatingWriter failed: %v", err)
+	}
+	defer w.Close()
+
+	w.Write([]byte("line\n"))
+	if err := w.Rotate(); err != nil {
+		t.Fatalf("Rotate failed: %v", err)
+	}
+	w.Close()
+
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("Expected stale backup to be removed, stat err = %v", err)
+	}
+}
+
+func TestRotatingWriterCompress(t *testing.T) {
+	fakeClock(t, time.Date(2024, 2, 10, 0, 0, 0, 0, time.UTC))
+	dir := t.TempDir()
+
+	w, err := NewRotatingWriter(filepath.Join(dir, "app.log"), RotateConfig{Compress: true})
+	if err != nil {
+		t.Fatalf("NewRotatingWriter failed: %v", err)
+	}
+
+	w.Write([]byte("compressed line\n"))
+	if err := w.Rotate(); err != nil {
+		t.Fatalf("Rotate failed: %v", err)
+	}
+	w.Close()
+
+	backups, err := w.backups()
+	if err != nil {
+		t.Fatalf("backups failed: %v", err)
+	}
+	if len(backups) != 1 {
+		t.Fatalf("Expected 1 backup, got %d", len(backups))
+	}
+	if !backups[0].compressed || !strings.HasSuffix(backups[0].path, ".log.gz") {
+		t.Fatalf("Expected compressed backup, got %q", backups[0].path)
+	}
+
+	f, err := os.Open(backups[0].path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("gzip.NewReader failed: %v", err)
+	}
+	data, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatalf("ReadAll failed: %v", err)
+	}
+	if string(data) != "compressed line\n" {
+		t.Errorf("Unexpected backup content %q", data)
+	}
+}
+
+func TestRotatingWriterMaxTotalSize(t *testing.T) {
+	fakeClock(t, time.Date(2024, 2, 10, 0, 0, 0, 0, time.UTC))
+	dir := t.TempDir()
+
+	w, err := NewRotatingWriter(filepath.Join(dir, "app.log"), RotateConfig{MaxTotalSize: 1})
+	if err != nil {
+		t.Fatalf("NewRotatingWriter failed: %v", err)
+	}
+
+	chunk := []byte(strings.Repeat("x", megabyte/3))
+	for i := 0; i < 4; i++ {
+		w.Write(chunk)
+		if err := w.Rotate(); err != nil {
+			t.Fatalf("Rotate failed: %v", err)
+		}
+	}
+	w.Close()
+
+	backups, err := w.backups()
+	if err != nil {
+		t.Fatalf("backups failed: %v", err)
+	}
+	var total int64
+	for _, b := range backups {
+		total += b.size
+	}
+	if total > megabyte {
+		t.Errorf("Expected backups to fit in 1MB, got %d bytes in %d files", total, len(backups))
+	}
+	if len(backups) != 3 {
+		t.Errorf("Expected the 3 newest backups to be kept, got %d", len(backups))
+	}
+}
+
+func TestRotateOutputPath(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+
+	logger, err := NewLoggerWithConfig(Config{
+		Level:       InfoLevel,
+		Encoding:    "json",
+		OutputPaths: []string{"rotate://" + filename + "?max_size=1&max_backups=3"},
+	})
+	if err != nil {
+		t.Fatalf("NewLoggerWithConfig failed: %v", err)
+	}
+
+	logger.Info("rotating output")
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if !strings.Contains(string(data), "rotating output") {
+		t.Errorf("Expected log line in file, got %q", data)
+	}
+}
+
+func TestRotateOutputPathInvalidOption(t *testing.T) {
+	_, err := NewLoggerWithConfig(Config{
+		Level:       InfoLevel,
+		Encoding:    "json",
+		OutputPaths: []string{"rotate://" + filepath.Join(t.TempDir(), "app.log") + "?max_size=big"},
+	})
+	if err == nil {
+		t.Fatal("Expected error for invalid max_size")
+	}
+}
+
+func TestConfigRotate(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+
+	logger, err := NewLoggerWithConfig(Config{
+		Level:       InfoLevel,
+		Encoding:    "json",
+		OutputPaths: []string{"stdout", filename},
+		Rotate:      &RotateConfig{MaxSize: 10, MaxBackups: 3},
+	})
+	if err != nil {
+		t.Fatalf("NewLoggerWithConfig failed: %v", err)
+	}
+	defer logger.Close()
+
+	outputs := logger.config.base.Load().sinks
+	if len(outputs.closers) != 2 {
+		t.Fatalf("Expected 2 outputs, got %d", len(outputs.closers))
+	}
+	if _, ok := outputs.closers[1].(*RotatingWriter); !ok {
+		t.Errorf("Expected file output to be a *RotatingWriter, got %T", outputs.closers[1])
+	}
+}
+
+func TestRotatingWriterClosed(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+
+	w, err := NewRotatingWriter(filename, RotateConfig{MaxSize: 1, MaxBackups: 1})
+	if err != nil {
+		t.Fatalf("NewRotatingWriter failed: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if err := os.Remove(filename); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+
+	if _, err := w.Write([]byte("late\n")); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Expected os.ErrClosed from Write after Close, got %v", err)
+	}
+	if err := w.Rotate(); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Expected os.ErrClosed from Rotate after Close, got %v", err)
+	}
+	if err := w.Reopen(); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Expected os.ErrClosed from Reopen after Close, got %v", err)
+	}
+	if _, err := os.Stat(filename); !os.IsNotExist(err) {
+		t.Errorf("Expected the file not to be recreated after Close, got %v", err)
+	}
+	if w.millCh != nil {
+		t.Error("Expected no background goroutine after Close")
+	}
+}
@@ -0,0 +1,183 @@
+package golog
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// samplingCounters is the number of counters per level; messages are
+// assigned to counters by hash, so distinct messages may share one
+const samplingCounters = 4096
+
+// defaultSamplingTick is the sampling interval used when Tick is zero
+const defaultSamplingTick = time.Second
+
+// SamplingConfig limits how many entries with the same level and message
+// are logged per Tick: the first Initial are logged, then every
+// Thereafter-th, and the rest are dropped. This keeps hot loops from
+// flooding the outputs while still showing that they are running.
+//
+// Example:
+//
+//	config.Sampling = &golog.SamplingConfig{
+//		Initial:    100,
+//		Thereafter: 100,
+//		Levels:     map[golog.Level]golog.SamplingRate{golog.ErrorLevel: {Thereafter: 1}},
+//		OnDropped: func(level golog.Level, msg string, dropped uint64) {
+//			droppedEntries.WithLabelValues(level.String()).Inc()
+//		},
+//	}
+type SamplingConfig struct {
+	// Initial is the number of entries logged per Tick before sampling starts
+	Initial int `json:"initial" yaml:"initial"`
+	// Thereafter logs every Thereafter-th entry after Initial; 0 drops them all
+	Thereafter int `json:"thereafter" yaml:"thereafter"`
+	// Tick is the interval over which entries are counted. Default: 1s
+	Tick time.Duration `json:"tick" yaml:"tick"`
+	// Levels overrides Initial and Thereafter for individual levels. Use
+	// {Thereafter: 1} to log every entry at a level.
+	Levels map[Level]SamplingRate `json:"levels" yaml:"levels"`
+	// OnDropped, if set, is called for every dropped entry with the number of
+	// entries with the same level and message dropped so far in this Tick.
+	// It must be safe for concurrent use and must not log through the same
+	// logger.
+	OnDropped func(level Level, msg string, dropped uint64) `json:"-" yaml:"-"`
+}
+
+// SamplingRate overrides SamplingConfig.Initial and Thereafter for one level
+type SamplingRate struct {
+	Initial    int `json:"initial" yaml:"initial"`
+	Thereafter int `json:"thereafter" yaml:"thereafter"`
+}
+
+// numLevels is the number of golog levels, from TraceLevel to PanicLevel
+const numLevels = int(PanicLevel-TraceLevel) + 1
+
+// samplingCore drops entries beyond the configured rate. Unlike zap's
+// sampler, it counts golog's extra levels too.
+type samplingCore struct {
+	zapcore.Core
+	*sampler
+}
+
+// sampler holds the rates and counters shared by a samplingCore and the
+// cores derived from it with With
+type sampler struct {
+	tick      time.Duration
+	rates     [numLevels]SamplingRate
+	counters  [numLevels][samplingCounters]samplingCounter
+	onDropped func(level Level, msg string, dropped uint64)
+}
+
+// newSamplingCore wraps core with the sampling described by config
+func newSamplingCore(core zapcore.Core, config SamplingConfig) *samplingCore {
+	s := &sampler{tick: config.Tick, onDropped: config.OnDropped}
+	if s.tick <= 0 {
+		s.tick = defaultSamplingTick
+	}
+	for i := range s.rates {
+		rate, ok := config.Levels[TraceLevel+Level(i)]
+		if !ok {
+			rate = SamplingRate{Initial: config.Initial, Thereafter: config.Thereafter}
+		}
+		s.rates[i] = rate
+	}
+	return &samplingCore{Core: core, sampler: s}
+}
+
+// With adds structured context to the wrapped core, sharing the counters
+func (c *samplingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &samplingCore{Core: c.Core.With(fields), sampler: c.sampler}
+}
+
+// Check drops the entry if its level and message are over the rate
+func (c *samplingCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Enabled(ent.Level) {
+		return ce
+	}
+
+	level := fromZapLevel(ent.Level)
+	i := int(level - TraceLevel)
+	rate := c.rates[i]
+	counter := &c.counters[i][messageHash(ent.Message)%samplingCounters]
+
+	n := counter.inc(ent.Time, c.tick)
+	initial, thereafter := uint64(rate.Initial), uint64(rate.Thereafter)
+	if n <= initial || (thereafter > 0 && (n-initial)%thereafter == 0) {
+		return c.Core.Check(ent, ce)
+	}
+
+	if c.onDropped != nil {
+		dropped := n - initial
+		if thereafter > 0 {
+			dropped -= (n - initial) / thereafter
+		}
+		c.onDropped(level, ent.Message, dropped)
+	}
+	return ce
+}
+
+// samplingCounter counts entries for one level and message hash per tick
+type samplingCounter struct {
+	resetAt atomic.Int64
+	count   atomic.Uint64
+}
+
+// inc counts an entry logged at t and returns the count for the current
+// tick, starting a new tick if the previous one has ended
+func (c *samplingCounter) inc(t time.Time, tick time.Duration) uint64 {
+	now := t.UnixNano()
+	resetAt := c.resetAt.Load()
+	if resetAt > now {
+		return c.count.Add(1)
+	}
+
+	c.count.Store(1)
+	if !c.resetAt.CompareAndSwap(resetAt, now+tick.Nanoseconds()) {
+		// another goroutine started the tick first
+		return c.count.Add(1)
+	}
+	return 1
+}
+
+// messageHash returns the FNV-1a hash of msg without allocating
+func messageHash(msg string) uint32 {
+	const (
+		offset32 = 2166136261
+		prime32  = 16777619
+	)
+	h := uint32(offset32)
+	for i := 0; i < len(msg); i++ {
+		h ^= uint32(msg[i])
+		h *= prime32
+	}
+	return h
+}
+
+// validate adds an error to errs for every invalid setting in c
+func (c SamplingConfig) validate(key string, errs *ConfigErrors) {
+	checkRate := func(key string, rate SamplingRate) {
+		if rate.Initial < 0 {
+			errs.add(joinKey(key, "initial"), fmt.Errorf("must not be negative, got %d", rate.Initial))
+		}
+		if rate.Thereafter < 0 {
+			errs.add(joinKey(key, "thereafter"), fmt.Errorf("must not be negative, got %d", rate.Thereafter))
+		}
+	}
+
+	checkRate(key, SamplingRate{Initial: c.Initial, Thereafter: c.Thereafter})
+	if c.Tick < 0 {
+		errs.add(joinKey(key, "tick"), fmt.Errorf("must not be negative, got %v", c.Tick))
+	}
+	for level, rate := range c.Levels {
+		levelKey := joinKey(joinKey(key, "levels"), level.String())
+		if !validLevel(level) {
+			errs.add(levelKey, fmt.Errorf("unknown level %d", level))
+			continue
+		}
+		checkRate(levelKey, rate)
+	}
+}
@@ -0,0 +1,159 @@
+package golog
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestSamplingCore(t *testing.T) {
+	obs, logs := observer.New(zapTraceLevel)
+	var drops atomic.Uint64
+	var lastDropped atomic.Uint64
+	core := newSamplingCore(obs, SamplingConfig{
+		Initial:    2,
+		Thereafter: 3,
+		Levels:     map[Level]SamplingRate{ErrorLevel: {Thereafter: 1}},
+		OnDropped: func(level Level, msg string, dropped uint64) {
+			drops.Add(1)
+			lastDropped.Store(dropped)
+		},
+	})
+
+	now := time.Now()
+	write := func(lvl zapcore.Level, msg string, t time.Time) {
+		ent := zapcore.Entry{Level: lvl, Message: msg, Time: t}
+		if ce := core.Check(ent, nil); ce != nil {
+			ce.Write()
+		}
+	}
+
+	for i := 0; i < 10; i++ {
+		write(zapNoticeLevel, "hot loop", now)
+		write(zapcore.ErrorLevel, "failure", now)
+	}
+	// entries 1, 2, 5 and 8 are logged; the rest are dropped
+	if got := logs.FilterMessage("hot loop").Len(); got != 4 {
+		t.Errorf("Expected 4 sampled notice entries, got %d", got)
+	}
+	if got := logs.FilterMessage("failure").Len(); got != 10 {
+		t.Errorf("Expected every error entry, got %d", got)
+	}
+	if drops.Load() != 6 || lastDropped.Load() != 6 {
+		t.Errorf("Expected 6 drops reported with a final count of 6, got %d and %d", drops.Load(), lastDropped.Load())
+	}
+
+	// a new tick starts counting again
+	write(zapNoticeLevel, "hot loop", now.Add(2*time.Second))
+	if got := logs.FilterMessage("hot loop").Len(); got != 5 {
+		t.Errorf("Expected an entry logged in the next tick, got %d entries", got)
+	}
+
+	// children share counters
+	child := core.With([]zapcore.Field{zapcore.Field{Key: "k", Type: zapcore.StringType, String: "v"}})
+	if ce := child.Check(zapcore.Entry{Level: zapNoticeLevel, Message: "hot loop", Time: now.Add(2 * time.Second)}, nil); ce != nil {
+		ce.Write()
+	}
+	if got := logs.FilterMessage("hot loop").Len(); got != 6 {
+		t.Errorf("Expected second entry of the tick from child, got %d entries", got)
+	}
+}
+
+func TestSamplingConfig(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "app.log")
+	config := DefaultConfig()
+	config.OutputPaths = []string{filename}
+	config.Sampling = &SamplingConfig{Initial: 3}
+	logger := newFileLogger(t, config)
+
+	for i := 0; i < 10; i++ {
+		logger.Info("repeated")
+		logger.With(Field("i", i)).Warn("repeated")
+	}
+	logger.Sync()
+
+	if got := strings.Count(readFile(t, filename), "repeated"); got != 6 {
+		t.Errorf("Expected 3 entries per level, got %d", got)
+	}
+}
+
+func TestSamplingLoadConfig(t *testing.T) {
+	path := writeConfigFile(t, "log.yaml", `
+sampling:
+  initial: 100
+  thereafter: 10
+  tick: 2s
+  levels:
+    error: {thereafter: 1}
+    bogus: {initial: 1}
+`)
+
+	config, err := LoadConfig(path)
+	var errs ConfigErrors
+	if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Key != "sampling.levels" {
+		t.Fatalf("Expected error for unknown level in sampling.levels, got %v", err)
+	}
+
+	path = writeConfigFile(t, "log.yaml", `
+sampling:
+  initial: 100
+  thereafter: 10
+  tick: 2s
+  levels:
+    error: {thereafter: 1}
+`)
+	config, err = LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	s := config.Sampling
+	if s == nil || s.Initial != 100 || s.Thereafter != 10 || s.Tick != 2*time.Second || s.Levels[ErrorLevel].Thereafter != 1 {
+		t.Errorf("Unexpected sampling config %+v", s)
+	}
+}
+
+func TestSamplingValidate(t *testing.T) {
+	config := DefaultConfig()
+	config.Sampling = &SamplingConfig{
+		Initial: -1,
+		Tick:    -time.Second,
+		Levels:  map[Level]SamplingRate{WarnLevel: {Thereafter: -1}},
+	}
+
+	var errs ConfigErrors
+	if !errors.As(config.Validate(), &errs) {
+		t.Fatal("Expected ConfigErrors")
+	}
+	var keys []string
+	for _, e := range errs {
+		keys = append(keys, e.Key)
+	}
+	want := "sampling.initial,sampling.tick,sampling.levels.warn.thereafter"
+	if got := strings.Join(keys, ","); got != want {
+		t.Errorf("Expected errors for %s, got %s", want, got)
+	}
+}
+
+func TestProductionLoggerSampling(t *testing.T) {
+	logger, err := NewProductionLogger()
+	if err != nil {
+		t.Fatalf("NewProductionLogger failed: %v", err)
+	}
+	core, ok := logger.logger.Core().(*levelCore)
+	if !ok {
+		t.Fatalf("Expected *levelCore, got %T", logger.logger.Core())
+	}
+	sampling, ok := core.Core.(*samplingCore)
+	if !ok {
+		t.Fatalf("Expected production logger to sample, got %T", core.Core)
+	}
+	if rate := sampling.rates[NoticeLevel-TraceLevel]; rate.Initial != 100 || rate.Thereafter != 100 {
+		t.Errorf("Expected 100/100 sampling for notice entries, got %+v", rate)
+	}
+}
@@ -0,0 +1,277 @@
+package golog
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// sinkSet tracks the outputs opened for a logger so they can be reopened
+// and closed together
+type sinkSet struct {
+	mu      sync.Mutex
+	closers []io.Closer
+	// stops ends the signal handlers and config watchers tied to the outputs
+	stops []func()
+	// errorOutput receives failures that can't be returned to a caller
+	errorOutput zapcore.WriteSyncer
+}
+
+// reopener is implemented by outputs backed by a named file
+type reopener interface {
+	Reopen() error
+}
+
+// closerFunc adapts the close function returned by zap.Open to io.Closer
+type closerFunc func()
+
+func (f closerFunc) Close() error {
+	f()
+	return nil
+}
+
+// open opens every path and combines them into a single WriteSyncer.
+// Paths using the rotate:// scheme, and plain file paths when rotate is
+// non-nil, are written through a RotatingWriter; other file paths are opened
+// as reopenable files and everything else is opened by zap.
+func (s *sinkSet) open(paths []string, rotate *RotateConfig) (zapcore.WriteSyncer, error) {
+	writers := make([]zapcore.WriteSyncer, 0, len(paths))
+	for _, path := range paths {
+		ws, err := s.openPath(path, rotate)
+		if err != nil {
+			return nil, err
+		}
+		writers = append(writers, ws)
+	}
+	return zap.CombineWriteSyncers(writers...), nil
+}
+
+// openPath opens a single output path
+func (s *sinkSet) openPath(path string, rotate *RotateConfig) (zapcore.WriteSyncer, error) {
+	if strings.HasPrefix(path, rotateScheme+"://") {
+		var base RotateConfig
+		if rotate != nil {
+			base = *rotate
+		}
+		filename, config, err := parseRotateURL(path, base)
+		if err != nil {
+			return nil, err
+		}
+		w, err := NewRotatingWriter(filename, config)
+		if err != nil {
+			return nil, err
+		}
+		s.closers = append(s.closers, w)
+		return w, nil
+	}
+
+	if rotate != nil && isFilePath(path) {
+		w, err := NewRotatingWriter(strings.TrimPrefix(path, "file://"), *rotate)
+		if err != nil {
+			return nil, err
+		}
+		s.closers = append(s.closers, w)
+		return w, nil
+	}
+
+	if isFilePath(path) {
+		f, err := openFileSink(strings.TrimPrefix(path, "file://"))
+		if err != nil {
+			return nil, err
+		}
+		s.closers = append(s.closers, f)
+		return f, nil
+	}
+
+	ws, closeFn, err := zap.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	s.closers = append(s.closers, closerFunc(closeFn))
+	return ws, nil
+}
+
+// Reopen closes and reopens every file output, so that writes go to a new
+// file after the old one was moved away by an external tool such as logrotate
+func (s *sinkSet) Reopen() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var errs []error
+	for _, c := range s.closers {
+		if r, ok := c.(reopener); ok {
+			errs = append(errs, r.Reopen())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// reopenOnSignal calls Reopen whenever one of sigs is received until the
+// returned function is called. Failures are written to s.errorOutput.
+func (s *sinkSet) reopenOnSignal(sigs ...os.Signal) func() {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{syscall.SIGHUP}
+	}
+
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, sigs...)
+
+	go func() {
+		for {
+			select {
+			case <-ch:
+				if err := s.Reopen(); err != nil && s.errorOutput != nil {
+					fmt.Fprintf(s.errorOutput, "golog: reopen failed: %v\n", err)
+					s.errorOutput.Sync()
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	stop := func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+	}
+
+	s.addStop(stop)
+	return stop
+}
+
+// addStop registers stop to be called when the set is closed
+func (s *sinkSet) addStop(stop func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stops = append(s.stops, stop)
+}
+
+// Close stops signal handlers and config watchers and closes every output
+// in the set
+func (s *sinkSet) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, stop := range s.stops {
+		stop()
+	}
+	s.stops = nil
+
+	var errs []error
+	for _, c := range s.closers {
+		errs = append(errs, c.Close())
+	}
+	s.closers = nil
+	return errors.Join(errs...)
+}
+
+// fileSink is a plain file output that can be reopened by name
+type fileSink struct {
+	path string
+
+	mu   sync.Mutex
+	file *os.File
+	// closed is set by Close; file is also nil after a failed Reopen, which
+	// the next Write retries
+	closed bool
+}
+
+// openFileSink opens path for appending, creating it if needed
+func openFileSink(path string) (*fileSink, error) {
+	f := &fileSink{path: path}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// open opens the file; the caller must hold f.mu or own f exclusively
+func (f *fileSink) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o666)
+	if err != nil {
+		return fmt.Errorf("golog: can't open log file: %w", err)
+	}
+	f.file = file
+	return nil
+}
+
+// Write writes p to the file, opening it again first if a Reopen failed.
+// Writes after Close fail with os.ErrClosed.
+func (f *fileSink) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return 0, os.ErrClosed
+	}
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+	return f.file.Write(p)
+}
+
+// Sync commits the file's contents to stable storage
+func (f *fileSink) Sync() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	return f.file.Sync()
+}
+
+// Reopen closes the file and opens path again
+func (f *fileSink) Reopen() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return os.ErrClosed
+	}
+	if f.file != nil {
+		f.file.Close()
+		f.file = nil
+	}
+	return f.open()
+}
+
+// Close closes the file
+func (f *fileSink) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.closed = true
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// isFilePath reports whether path refers to a regular file rather than
+// stdout, stderr or a URL with another scheme
+func isFilePath(path string) bool {
+	if path == "stdout" || path == "stderr" {
+		return false
+	}
+	if strings.HasPrefix(path, "file://") {
+		return true
+	}
+	return !strings.Contains(path, "://")
+}
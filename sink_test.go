@@ -0,0 +1,121 @@
+package golog
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newFileLogger(t *testing.T, config Config) *Logger {
+	t.Helper()
+	if config.Encoding == "" {
+		config.Encoding = "json"
+	}
+	logger, err := NewLoggerWithConfig(config)
+	if err != nil {
+		t.Fatalf("NewLoggerWithConfig failed: %v", err)
+	}
+	t.Cleanup(func() { logger.Close() })
+	return logger
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	return string(data)
+}
+
+func TestLoggerReopen(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+	moved := filepath.Join(dir, "app.log.1")
+
+	logger := newFileLogger(t, Config{Level: InfoLevel, OutputPaths: []string{filename}})
+
+	logger.Info("before rotate")
+	if err := os.Rename(filename, moved); err != nil {
+		t.Fatalf("Rename failed: %v", err)
+	}
+	if err := logger.Reopen(); err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	logger.Info("after rotate")
+	logger.Sync()
+
+	if got := readFile(t, moved); !strings.Contains(got, "before rotate") || strings.Contains(got, "after rotate") {
+		t.Errorf("Unexpected content in moved file: %q", got)
+	}
+	if got := readFile(t, filename); !strings.Contains(got, "after rotate") {
+		t.Errorf("Expected new file to receive logs, got %q", got)
+	}
+}
+
+func TestLoggerReopenRotatingWriter(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+
+	logger := newFileLogger(t, Config{
+		Level:       InfoLevel,
+		OutputPaths: []string{filename},
+		Rotate:      &RotateConfig{},
+	})
+
+	logger.Info("before rotate")
+	os.Rename(filename, filepath.Join(dir, "moved.log"))
+	if err := logger.Reopen(); err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	logger.Info("after rotate")
+
+	if got := readFile(t, filename); !strings.Contains(got, "after rotate") {
+		t.Errorf("Expected new file to receive logs, got %q", got)
+	}
+}
+
+func TestReopenWithoutFiles(t *testing.T) {
+	logger := NewLogger()
+	if err := logger.Reopen(); err != nil {
+		t.Errorf("Reopen returned unexpected error: %v", err)
+	}
+	logger.ReopenOnSignal()()
+}
+
+func TestLoggerWriteAfterClose(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+
+	logger := newFileLogger(t, Config{
+		Level:            InfoLevel,
+		OutputPaths:      []string{filename},
+		ErrorOutputPaths: []string{filepath.Join(dir, "error.log")},
+	})
+	child := logger.With(Field("component", "worker"))
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if err := os.Remove(filename); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+
+	child.Info("after close")
+	if _, err := os.Stat(filename); !os.IsNotExist(err) {
+		t.Errorf("Expected the file not to be recreated after Close, got %v", err)
+	}
+
+	f, err := openFileSink(filepath.Join(dir, "sink.log"))
+	if err != nil {
+		t.Fatalf("openFileSink failed: %v", err)
+	}
+	f.Close()
+	if _, err := f.Write([]byte("late\n")); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Expected os.ErrClosed from Write after Close, got %v", err)
+	}
+	if err := f.Reopen(); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Expected os.ErrClosed from Reopen after Close, got %v", err)
+	}
+}
@@ -0,0 +1,36 @@
+//go:build unix
+
+package golog
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestLoggerReopenOnSignal(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+
+	logger := newFileLogger(t, Config{Level: InfoLevel, OutputPaths: []string{filename}})
+	stop := logger.ReopenOnSignal(syscall.SIGUSR1)
+	defer stop()
+
+	os.Rename(filename, filepath.Join(dir, "moved.log"))
+	if err := syscall.Kill(os.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Skipf("can't send signal: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, err := os.Stat(filename); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Log file was not reopened after signal")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
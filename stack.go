@@ -0,0 +1,82 @@
+package golog
+
+import (
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// stackConfig describes when stack traces are captured and how they are
+// formatted, from Config.StacktraceLevel, DisableStacktrace, StacktraceDepth
+// and DisableCallerTrim
+type stackConfig struct {
+	level    Level
+	disabled bool
+	// depth is the maximum number of frames kept; 0 keeps them all
+	depth int
+	// trim shortens file paths the way caller paths are shortened
+	trim bool
+}
+
+// newStackConfig returns the stack trace settings of config. Stack traces
+// default to ErrorLevel, or WarnLevel in development.
+func newStackConfig(config Config) *stackConfig {
+	sc := &stackConfig{
+		level:    ErrorLevel,
+		disabled: config.DisableStacktrace,
+		depth:    config.StacktraceDepth,
+		trim:     !config.DisableCallerTrim,
+	}
+	if config.Development {
+		sc.level = WarnLevel
+	}
+	if config.StacktraceLevel != nil {
+		sc.level = *config.StacktraceLevel
+	}
+	return sc
+}
+
+// enabled reports whether entries at lvl capture a stack trace
+func (sc *stackConfig) enabled(lvl zapcore.Level) bool {
+	return !sc.disabled && sc.level.Enabled(lvl)
+}
+
+// format limits a stack trace captured by zap to depth frames and trims its
+// file paths. zap formats each frame as the function name followed by a line
+// with a tab and file:line.
+func (sc *stackConfig) format(stack string) string {
+	if stack == "" || (sc.depth == 0 && !sc.trim) {
+		return stack
+	}
+
+	lines := strings.Split(stack, "\n")
+	if sc.depth > 0 && len(lines) > 2*sc.depth {
+		lines = lines[:2*sc.depth]
+	}
+	if sc.trim {
+		for i := 1; i < len(lines); i += 2 {
+			lines[i] = trimFrame(lines[i])
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// trimFrame shortens the path in a "\tfile:line" frame line to the package
+// directory and file, as ShortCallerEncoder does for the caller
+func trimFrame(line string) string {
+	location, ok := strings.CutPrefix(line, "\t")
+	if !ok {
+		return line
+	}
+	i := strings.LastIndexByte(location, ':')
+	if i < 0 {
+		return line
+	}
+	n, err := strconv.Atoi(location[i+1:])
+	if err != nil {
+		return line
+	}
+	caller := zapcore.EntryCaller{Defined: true, File: location[:i], Line: n}
+	return "\t" + caller.TrimmedPath()
+}
@@ -0,0 +1,160 @@
+package golog
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// stackEntries decodes the JSON entries in filename
+func stackEntries(t *testing.T, filename string) []map[string]any {
+	t.Helper()
+	var entries []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(readFile(t, filename)), "\n") {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Unmarshal %q failed: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestStacktraceConfig(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "app.log")
+	level := NoticeLevel
+	config := DefaultConfig()
+	config.OutputPaths = []string{filename}
+	config.StacktraceLevel = &level
+	config.StacktraceDepth = 2
+	logger := newFileLogger(t, config)
+
+	logger.Info("no stack")
+	logger.Notice("with stack")
+	logger.Sync()
+
+	entries := stackEntries(t, filename)
+	if _, ok := entries[0]["stacktrace"]; ok {
+		t.Errorf("Expected no stack trace below StacktraceLevel, got %v", entries[0])
+	}
+	stack, _ := entries[1]["stacktrace"].(string)
+	lines := strings.Split(stack, "\n")
+	if len(lines) != 4 {
+		t.Fatalf("Expected 2 frames, got %q", stack)
+	}
+	if !strings.Contains(lines[0], "TestStacktraceConfig") {
+		t.Errorf("Expected the first frame to be the caller, got %q", lines[0])
+	}
+	if want := "\t" + filepath.Base(mustAbs(t, ".")) + "/stack_test.go:"; !strings.HasPrefix(lines[1], want) {
+		t.Errorf("Expected a trimmed frame path, got %q", lines[1])
+	}
+}
+
+func TestStacktraceFullPath(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "app.log")
+	config := DefaultConfig()
+	config.OutputPaths = []string{filename}
+	config.DisableCallerTrim = true
+	logger := newFileLogger(t, config)
+
+	logger.Error("failed")
+	logger.Sync()
+
+	stack, _ := stackEntries(t, filename)[0]["stacktrace"].(string)
+	if want := "\t" + filepath.Join(mustAbs(t, "."), "stack_test.go:"); !strings.Contains(stack, want) {
+		t.Errorf("Expected full frame paths containing %q, got %q", want, stack)
+	}
+}
+
+func TestDisableStacktrace(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "app.log")
+	config := DefaultConfig()
+	config.OutputPaths = []string{filename}
+	config.DisableStacktrace = true
+	logger := newFileLogger(t, config)
+
+	logger.Error("failed")
+	logger.Sync()
+
+	if _, ok := stackEntries(t, filename)[0]["stacktrace"]; ok {
+		t.Error("Expected no stack trace when disabled")
+	}
+
+	// ApplyConfig turns them back on
+	config.DisableStacktrace = false
+	if err := logger.ApplyConfig(config); err != nil {
+		t.Fatalf("ApplyConfig failed: %v", err)
+	}
+	logger.Error("failed again")
+	logger.Sync()
+
+	if _, ok := stackEntries(t, filename)[1]["stacktrace"]; !ok {
+		t.Error("Expected a stack trace after ApplyConfig")
+	}
+}
+
+func TestStacktraceFormat(t *testing.T) {
+	stack := "main.handle\n\t/src/app/internal/server/handler.go:42\nmain.main\n\t/src/app/main.go:10"
+
+	tests := []struct {
+		name string
+		sc   stackConfig
+		want string
+	}{
+		{"unchanged", stackConfig{}, stack},
+		{"trimmed", stackConfig{trim: true}, "main.handle\n\tserver/handler.go:42\nmain.main\n\tapp/main.go:10"},
+		{"depth", stackConfig{depth: 1}, "main.handle\n\t/src/app/internal/server/handler.go:42"},
+		{"deeper than stack", stackConfig{depth: 5}, stack},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.sc.format(stack); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestStacktraceValidate(t *testing.T) {
+	level := Level(42)
+	config := DefaultConfig()
+	config.StacktraceLevel = &level
+	config.StacktraceDepth = -1
+
+	var errs ConfigErrors
+	if !errors.As(config.Validate(), &errs) {
+		t.Fatal("Expected ConfigErrors")
+	}
+	var keys []string
+	for _, e := range errs {
+		keys = append(keys, e.Key)
+	}
+	if got, want := strings.Join(keys, ","), "stacktraceLevel,stacktraceDepth"; got != want {
+		t.Errorf("Expected errors for %s, got %s", want, got)
+	}
+}
+
+func TestStacktraceFromEnv(t *testing.T) {
+	t.Setenv("GOLOG_STACKTRACE_LEVEL", "critical")
+	t.Setenv("GOLOG_STACKTRACE_DEPTH", "10")
+
+	config, err := ConfigFromEnv("GOLOG")
+	if err != nil {
+		t.Fatalf("ConfigFromEnv failed: %v", err)
+	}
+	if config.StacktraceLevel == nil || *config.StacktraceLevel != CriticalLevel || config.StacktraceDepth != 10 {
+		t.Errorf("Unexpected stacktrace settings %v and %d", config.StacktraceLevel, config.StacktraceDepth)
+	}
+}
+
+// mustAbs returns the absolute form of path
+func mustAbs(t *testing.T, path string) string {
+	t.Helper()
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		t.Fatalf("Abs failed: %v", err)
+	}
+	return abs
+}
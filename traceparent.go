@@ -0,0 +1,107 @@
+package golog
+
+import (
+	"context"
+	"strings"
+
+	"github.com/muleiwu/gsr"
+)
+
+// TraceparentHeader is the HTTP header carrying the W3C trace context
+const TraceparentHeader = "traceparent"
+
+// traceparentKey is the context key for the traceParent stored by
+// ContextWithTraceparent
+type traceparentKey struct{}
+
+// traceParent holds the IDs of a W3C traceparent header
+type traceParent struct {
+	traceID string
+	spanID  string
+	sampled bool
+}
+
+// ContextWithTraceparent returns a copy of ctx carrying the trace and parent
+// span IDs of a W3C traceparent header value, such as
+// "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01". Invalid values are
+// ignored, as the W3C Trace Context specification requires, and ctx is
+// returned unchanged. TraceparentExtractor adds the IDs to log entries.
+//
+// Example:
+//
+//	ctx := golog.ContextWithTraceparent(r.Context(), r.Header.Get(golog.TraceparentHeader))
+func ContextWithTraceparent(ctx context.Context, header string) context.Context {
+	tp, ok := parseTraceparent(header)
+	if !ok {
+		return ctx
+	}
+	return context.WithValue(ctx, traceparentKey{}, tp)
+}
+
+// TraceparentExtractor is a ContextExtractor that adds "trace_id",
+// "span_id" and "trace_sampled" fields from a traceparent stored with
+// ContextWithTraceparent. It lets services without an OpenTelemetry SDK
+// correlate their logs with traces:
+//
+//	golog.RegisterContextExtractor(golog.TraceparentExtractor)
+func TraceparentExtractor(ctx context.Context) []gsr.LoggerField {
+	tp, ok := ctx.Value(traceparentKey{}).(traceParent)
+	if !ok {
+		return nil
+	}
+	return []gsr.LoggerField{
+		Field("trace_id", tp.traceID),
+		Field("span_id", tp.spanID),
+		Field("trace_sampled", tp.sampled),
+	}
+}
+
+// parseTraceparent parses a traceparent header value: version, trace ID,
+// parent ID and flags as lowercase hex separated by dashes. Versions after 00
+// may append further fields, which are ignored.
+func parseTraceparent(header string) (traceParent, bool) {
+	header = strings.TrimSpace(header)
+	if len(header) < 55 || (len(header) > 55 && header[55] != '-') {
+		return traceParent{}, false
+	}
+	version, traceID, spanID, flags := header[0:2], header[3:35], header[36:52], header[53:55]
+	if header[2] != '-' || header[35] != '-' || header[52] != '-' {
+		return traceParent{}, false
+	}
+	if !isLowerHex(version) || version == "ff" || (version == "00" && len(header) != 55) {
+		return traceParent{}, false
+	}
+	if !isLowerHex(traceID) || !isLowerHex(spanID) || !isLowerHex(flags) {
+		return traceParent{}, false
+	}
+	if strings.Trim(traceID, "0") == "" || strings.Trim(spanID, "0") == "" {
+		return traceParent{}, false
+	}
+	return traceParent{
+		traceID: traceID,
+		spanID:  spanID,
+		sampled: hexValue(flags[1])&1 == 1,
+	}, true
+}
+
+// isLowerHex reports whether s consists only of lowercase hex digits
+func isLowerHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if hexValue(s[i]) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// hexValue returns the value of the lowercase hex digit c, or -1
+func hexValue(c byte) int {
+	switch {
+	case c >= '0' && c <= '9':
+		return int(c - '0')
+	case c >= 'a' && c <= 'f':
+		return int(c-'a') + 10
+	default:
+		return -1
+	}
+}
@@ -0,0 +1,54 @@
+package golog
+
+import (
+	"context"
+	"testing"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	tests := []struct {
+		header string
+		want   traceParent
+		ok     bool
+	}{
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", traceParent{"4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7", true}, true},
+		{" 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00 ", traceParent{"4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7", false}, true},
+		{"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-03-future", traceParent{"4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7", true}, true},
+		{"", traceParent{}, false},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", traceParent{}, false},
+		{"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01x", traceParent{}, false},
+		{"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", traceParent{}, false},
+		{"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", traceParent{}, false},
+		{"00-00000000000000000000000000000000-00f067aa0ba902b7-01", traceParent{}, false},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", traceParent{}, false},
+		{"00_4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", traceParent{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseTraceparent(tt.header)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("parseTraceparent(%q) = %+v, %v; want %+v, %v", tt.header, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestTraceparentExtractor(t *testing.T) {
+	t.Cleanup(func() { extractors.Store(nil) })
+	RegisterContextExtractor(TraceparentExtractor)
+	logger, logs := newObservedLogger(InfoLevel)
+
+	ctx := ContextWithTraceparent(context.Background(), "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	logger.InfoContext(ctx, "traced")
+	logger.InfoContext(ContextWithTraceparent(context.Background(), "garbage"), "untraced")
+
+	entries := logs.All()
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+	fields := entries[0].ContextMap()
+	if fields["trace_id"] != "4bf92f3577b34da6a3ce929d0e0e4736" || fields["span_id"] != "00f067aa0ba902b7" || fields["trace_sampled"] != true {
+		t.Errorf("Expected trace fields, got %v", fields)
+	}
+	if len(entries[1].Context) != 0 {
+		t.Errorf("Expected no fields for an invalid traceparent, got %v", entries[1].ContextMap())
+	}
+}
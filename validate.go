@@ -0,0 +1,276 @@
+package golog
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxCallerSkip bounds Config.CallerSkip; deeper wrapper stacks are almost
+// certainly a mistake
+const maxCallerSkip = 32
+
+// ConfigErrors lists every problem found in a configuration. It is returned
+// by Config.Validate, LoadConfig and ConfigFromEnv, so all problems can be
+// reported at once:
+//
+//	if err := config.Validate(); err != nil {
+//		var errs golog.ConfigErrors
+//		if errors.As(err, &errs) {
+//			for _, e := range errs {
+//				fmt.Fprintf(os.Stderr, "%s: %v\n", e.Key, e.Err)
+//			}
+//		}
+//		os.Exit(2)
+//	}
+type ConfigErrors []*ConfigError
+
+// Error implements the error interface, with one problem per line
+func (e ConfigErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap returns the individual *ConfigError values for errors.Is and errors.As
+func (e ConfigErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+// add records a problem with key
+func (e *ConfigErrors) add(key string, err error) {
+	*e = append(*e, &ConfigError{Key: key, Err: err})
+}
+
+// err returns e as an error, or nil if it is empty
+func (e ConfigErrors) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Validate checks c and reports every problem found as ConfigErrors, keyed
+// like the config file: unknown levels, unknown or missing encodings,
+// invalid Encoder and Sampling settings, missing or unwritable output paths
+// in OutputPaths or Outputs, invalid rotate:// options, negative rotation
+// limits, invalid VModule and NamedLevels entries, empty InitialFields keys,
+// SplitStderr combined with Outputs, "combined" as the only encoding, an
+// unknown StacktraceLevel, a negative StacktraceDepth and an out-of-range
+// CallerSkip. File outputs are checked without creating them: an existing
+// file must be writable, and otherwise the directory it would be created in.
+//
+// NewLoggerWithConfig and ApplyConfig call Validate before building anything.
+func (c Config) Validate() error {
+	var errs ConfigErrors
+
+	if !validLevel(c.Level) {
+		errs.add("level", fmt.Errorf("unknown level %d", c.Level))
+	}
+
+	switch {
+	case c.SplitStderr && len(c.Outputs) > 0:
+		errs.add("splitStderr", errors.New("can't be combined with outputs"))
+	case c.SplitStderr:
+		checkEncoding(c.Encoding, "encoding", &errs)
+		if c.Encoding == "combined" {
+			errs.add("encoding", errCombinedOnly)
+		}
+	case len(c.Outputs) == 0:
+		checkEncoding(c.Encoding, "encoding", &errs)
+		if c.Encoding == "combined" {
+			errs.add("encoding", errCombinedOnly)
+		}
+		checkPaths(c.OutputPaths, c.Rotate, "outputPaths", &errs)
+	default:
+		combinedOnly := true
+		for i, out := range c.outputs() {
+			key := fmt.Sprintf("outputs[%d]", i)
+			if !validLevel(out.Level) {
+				errs.add(key+".level", fmt.Errorf("unknown level %d", out.Level))
+			}
+			checkEncoding(out.Encoding, key+".encoding", &errs)
+			combinedOnly = combinedOnly && out.Encoding == "combined"
+			checkPaths(out.Paths, out.Rotate, key+".paths", &errs)
+			if c.Outputs[i].Rotate != nil {
+				checkRotateConfig(*c.Outputs[i].Rotate, key+".rotate", &errs)
+			}
+		}
+		if combinedOnly {
+			errs.add("outputs", errCombinedOnly)
+		}
+	}
+	for i, path := range c.ErrorOutputPaths {
+		if err := checkOutputPath(path, nil); err != nil {
+			errs.add(fmt.Sprintf("errorOutputPaths[%d]", i), err)
+		}
+	}
+
+	for key := range c.InitialFields {
+		if strings.TrimSpace(key) == "" {
+			errs.add("initialFields", errors.New("empty field key"))
+		}
+	}
+
+	c.Encoder.validate("encoder", &errs)
+	if c.Sampling != nil {
+		c.Sampling.validate("sampling", &errs)
+	}
+
+	if c.StacktraceLevel != nil && !validLevel(*c.StacktraceLevel) {
+		errs.add("stacktraceLevel", fmt.Errorf("unknown level %d", *c.StacktraceLevel))
+	}
+	if c.StacktraceDepth < 0 {
+		errs.add("stacktraceDepth", fmt.Errorf("must not be negative, got %d", c.StacktraceDepth))
+	}
+
+	if c.CallerSkip > maxCallerSkip {
+		errs.add("callerSkip", fmt.Errorf("%d exceeds the maximum of %d", c.CallerSkip, maxCallerSkip))
+	}
+
+	if _, err := parseVModule(c.VModule); err != nil {
+		errs.add("vmodule", err)
+	}
+
+	for pattern, level := range c.NamedLevels {
+		key := joinKey("namedLevels", pattern)
+		if strings.TrimSpace(pattern) == "" {
+			errs.add(key, errors.New("empty name pattern"))
+		}
+		if !validLevel(level) {
+			errs.add(key, fmt.Errorf("unknown level %d", level))
+		}
+	}
+
+	if c.Rotate != nil {
+		checkRotateConfig(*c.Rotate, "rotate", &errs)
+	}
+
+	return errs.err()
+}
+
+// validLevel reports whether level is one of the defined levels
+func validLevel(level Level) bool {
+	return level >= TraceLevel && level <= PanicLevel
+}
+
+// errCombinedOnly reports a config whose only encoding is "combined", which
+// would drop every entry that isn't an access line
+var errCombinedOnly = errors.New(`"combined" only writes access lines and must be used in outputs next to another encoding`)
+
+// checkEncoding adds an error to errs unless encoding names a known encoder
+func checkEncoding(encoding, key string, errs *ConfigErrors) {
+	switch encoding {
+	case "json", "console", "combined":
+	case "":
+		errs.add(key, errors.New("no encoding specified"))
+	default:
+		errs.add(key, fmt.Errorf("unknown encoding %q, want json, console or combined", encoding))
+	}
+}
+
+// checkPaths adds an error to errs if paths is empty or has paths that
+// can't be opened
+func checkPaths(paths []string, rotate *RotateConfig, key string, errs *ConfigErrors) {
+	if len(paths) == 0 {
+		errs.add(key, errors.New("no output paths specified"))
+	}
+	for i, path := range paths {
+		if err := checkOutputPath(path, rotate); err != nil {
+			errs.add(fmt.Sprintf("%s[%d]", key, i), err)
+		}
+	}
+}
+
+// checkRotateConfig adds an error to errs for every negative limit in config
+func checkRotateConfig(config RotateConfig, path string, errs *ConfigErrors) {
+	limits := []struct {
+		key   string
+		value int
+	}{
+		{"maxSize", config.MaxSize},
+		{"maxAge", config.MaxAge},
+		{"maxBackups", config.MaxBackups},
+		{"maxTotalSize", config.MaxTotalSize},
+	}
+	for _, l := range limits {
+		if l.value < 0 {
+			errs.add(joinKey(path, l.key), fmt.Errorf("must not be negative, got %d", l.value))
+		}
+	}
+}
+
+// checkOutputPath reports whether path can be opened by sinkSet.open.
+// Outputs with schemes other than file:// and rotate:// are left to zap.
+func checkOutputPath(path string, rotate *RotateConfig) error {
+	if strings.HasPrefix(path, rotateScheme+"://") {
+		var base RotateConfig
+		if rotate != nil {
+			base = *rotate
+		}
+		filename, config, err := parseRotateURL(path, base)
+		if err != nil {
+			return err
+		}
+		var errs ConfigErrors
+		checkRotateConfig(config, "", &errs)
+		if len(errs) > 0 {
+			return fmt.Errorf("rotate option %s %w", errs[0].Key, errs[0].Err)
+		}
+		return checkWritable(filename, true)
+	}
+
+	if !isFilePath(path) {
+		return nil
+	}
+	return checkWritable(strings.TrimPrefix(path, "file://"), rotate != nil)
+}
+
+// checkWritable reports whether filename can be opened for appending. If
+// makeDirs is set, missing parent directories are acceptable as long as they
+// can be created, as RotatingWriter does.
+func checkWritable(filename string, makeDirs bool) error {
+	if f, err := os.OpenFile(filename, os.O_WRONLY|os.O_APPEND, 0); err == nil {
+		return f.Close()
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	dir := filepath.Dir(filename)
+	for makeDirs {
+		if _, err := os.Stat(dir); !errors.Is(err, fs.ErrNotExist) {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	fi, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	if !fi.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+
+	// Creating a scratch file is the only portable way to check that the
+	// directory is writable
+	f, err := os.CreateTemp(dir, ".golog-check-*")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
@@ -0,0 +1,82 @@
+package golog
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	dir := t.TempDir()
+	config := DefaultConfig()
+	config.OutputPaths = []string{"stdout", filepath.Join(dir, "app.log"), "rotate://" + filepath.Join(dir, "new", "app.log")}
+	config.NamedLevels = map[string]Level{"billing.*": DebugLevel}
+	config.Rotate = &RotateConfig{MaxSize: 10}
+
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Validate returned unexpected error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "app.log")); !os.IsNotExist(err) {
+		t.Errorf("Validate should not create output files, got %v", err)
+	}
+}
+
+func TestValidateErrors(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	config := Config{
+		Level:            Level(42),
+		Encoding:         "xml",
+		OutputPaths:      []string{"stdout", filepath.Join(file, "app.log"), "rotate://" + file + "?max_size=-1"},
+		ErrorOutputPaths: []string{filepath.Join(dir, "missing", "error.log")},
+		CallerSkip:       1000,
+		VModule:          "db",
+		NamedLevels:      map[string]Level{"db": Level(-9)},
+		Rotate:           &RotateConfig{MaxAge: -1},
+	}
+
+	err := config.Validate()
+	var errs ConfigErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Expected ConfigErrors, got %T: %v", err, err)
+	}
+
+	var keys []string
+	for _, e := range errs {
+		keys = append(keys, e.Key)
+	}
+	sort.Strings(keys)
+	want := []string{
+		"callerSkip", "encoding", "errorOutputPaths[0]", "level", "namedLevels.db",
+		"outputPaths[1]", "outputPaths[2]", "rotate.maxAge", "vmodule",
+	}
+	if strings.Join(keys, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected errors for %v, got %v", want, keys)
+	}
+
+	if got := err.Error(); strings.Count(got, "\n") != len(want)-1 || !strings.Contains(got, `golog: config encoding: unknown encoding "xml"`) {
+		t.Errorf("Unexpected error message: %q", got)
+	}
+
+	if _, err := NewLoggerWithConfig(config); !errors.As(err, &errs) {
+		t.Errorf("Expected NewLoggerWithConfig to return ConfigErrors, got %v", err)
+	}
+}
+
+func TestValidateEmpty(t *testing.T) {
+	err := Config{}.Validate()
+	var errs ConfigErrors
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("Expected errors for encoding and outputPaths, got %v", err)
+	}
+	if errs[0].Key != "encoding" || errs[1].Key != "outputPaths" {
+		t.Errorf("Unexpected keys %q and %q", errs[0].Key, errs[1].Key)
+	}
+}
@@ -0,0 +1,192 @@
+package golog
+
+import (
+	"fmt"
+	"path"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/muleiwu/gsr"
+	"go.uber.org/zap"
+)
+
+// verbosity holds the global V level and the per-file overrides set with
+// SetVModule. It is shared by a logger and all of its children.
+type verbosity struct {
+	level atomic.Int32
+
+	mu      sync.RWMutex
+	filters []vmoduleFilter
+	// hasFilters lets V skip the caller lookup when there are no filters
+	hasFilters atomic.Bool
+	// cache maps a call site's program counter to its V level
+	cache sync.Map
+}
+
+// vmoduleFilter sets the V level for source files matching pattern
+type vmoduleFilter struct {
+	pattern string
+	level   int32
+}
+
+// setVModule replaces the file filters with those parsed from spec
+func (v *verbosity) setVModule(spec string) error {
+	filters, err := parseVModule(spec)
+	if err != nil {
+		return err
+	}
+	v.setFilters(filters)
+	return nil
+}
+
+// setFilters replaces the file filters
+func (v *verbosity) setFilters(filters []vmoduleFilter) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.filters = filters
+	v.cache.Clear()
+	v.hasFilters.Store(len(filters) > 0)
+}
+
+// enabled reports whether V(level) is enabled for the call site skip frames
+// above the caller of enabled
+func (v *verbosity) enabled(level int, skip int) bool {
+	if int32(level) <= v.level.Load() {
+		return true
+	}
+	if !v.hasFilters.Load() {
+		return false
+	}
+
+	var pcs [1]uintptr
+	if runtime.Callers(skip+2, pcs[:]) == 0 {
+		return false
+	}
+	pc := pcs[0]
+
+	if cached, ok := v.cache.Load(pc); ok {
+		return int32(level) <= cached.(int32)
+	}
+
+	frame, _ := runtime.CallersFrames(pcs[:]).Next()
+	fileLevel := v.levelForFile(frame.File)
+	v.cache.Store(pc, fileLevel)
+	return int32(level) <= fileLevel
+}
+
+// levelForFile returns the V level of the first filter matching file,
+// or the global V level
+func (v *verbosity) levelForFile(file string) int32 {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	file = strings.TrimSuffix(file, ".go")
+	for _, f := range v.filters {
+		if matchFile(f.pattern, file) {
+			return f.level
+		}
+	}
+	return v.level.Load()
+}
+
+// matchFile reports whether a source file path (without .go) matches a
+// vmodule pattern. Patterns without a slash match the file's base name;
+// patterns with slashes match the same number of trailing path elements,
+// so "service/cron*" matches ".../pkg/service/cron_server.go".
+func matchFile(pattern, file string) bool {
+	elems := strings.Count(pattern, "/") + 1
+	parts := strings.Split(file, "/")
+	if len(parts) > elems {
+		parts = parts[len(parts)-elems:]
+	}
+	ok, _ := path.Match(pattern, strings.Join(parts, "/"))
+	return ok
+}
+
+// parseVModule parses a comma-separated list of pattern=N pairs,
+// e.g. "service/cron*=3,db=2"
+func parseVModule(spec string) ([]vmoduleFilter, error) {
+	var filters []vmoduleFilter
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		pattern, text, ok := strings.Cut(item, "=")
+		pattern = strings.TrimSuffix(strings.TrimSpace(pattern), ".go")
+		if !ok || pattern == "" {
+			return nil, fmt.Errorf("golog: invalid vmodule entry %q, want pattern=N", item)
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("golog: invalid vmodule pattern %q: %w", pattern, err)
+		}
+		level, err := strconv.ParseInt(strings.TrimSpace(text), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("golog: invalid vmodule level in %q: %w", item, err)
+		}
+		filters = append(filters, vmoduleFilter{pattern: pattern, level: int32(level)})
+	}
+	return filters, nil
+}
+
+// Verbose logs messages only when the V level it was created with is enabled.
+// Create one with Logger.V.
+type Verbose struct {
+	logger  *Logger
+	level   int
+	enabled bool
+}
+
+// Enabled reports whether logging through v produces output. Use it to
+// guard expensive argument construction:
+//
+//	if v := logger.V(3); v.Enabled() {
+//		v.Info("cache state", golog.Field("entries", dumpCache()))
+//	}
+func (v Verbose) Enabled() bool {
+	return v.enabled
+}
+
+// Info logs a message at InfoLevel with a "v" field holding the V level,
+// if v is enabled
+func (v Verbose) Info(format string, args ...gsr.LoggerField) {
+	if !v.enabled {
+		return
+	}
+	fields := append(v.logger.getFields(args...), zap.Int("v", v.level))
+	v.logger.logger.Info(format, fields...)
+}
+
+// V returns a Verbose that logs only if level is at most the global V level
+// set with Config.Verbosity or SetVerbosity, or the level of the first
+// Config.VModule filter matching the calling source file.
+// The result is cached per call site, so V is cheap when disabled.
+//
+// Example:
+//
+//	logger.V(2).Info("retrying", golog.Field("attempt", n))
+func (l *Logger) V(level int) Verbose {
+	return Verbose{
+		logger:  l,
+		level:   level,
+		enabled: l.verbosity.enabled(level, l.callerSkip+1),
+	}
+}
+
+// SetVerbosity changes the global V level at runtime
+func (l *Logger) SetVerbosity(level int) {
+	l.verbosity.level.Store(int32(level))
+	l.verbosity.cache.Clear()
+}
+
+// SetVModule replaces the per-file V levels with those in spec, a
+// comma-separated list of pattern=N pairs such as "service/cron*=3,db=2".
+// Patterns are matched against source file paths without the .go suffix;
+// see Config.VModule. An empty spec removes every filter.
+func (l *Logger) SetVModule(spec string) error {
+	return l.verbosity.setVModule(spec)
+}
@@ -0,0 +1,93 @@
+package golog
+
+import (
+	"testing"
+)
+
+func TestVerbosity(t *testing.T) {
+	logger, logs := newObservedLogger(InfoLevel)
+
+	logger.V(0).Info("v0")
+	logger.V(1).Info("v1 hidden")
+
+	logger.SetVerbosity(2)
+	logger.V(2).Info("v2")
+	logger.V(3).Info("v3 hidden")
+
+	entries := logs.All()
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+	if entries[1].Message != "v2" || entries[1].ContextMap()["v"] != int64(2) {
+		t.Errorf("Unexpected entry %+v", entries[1])
+	}
+}
+
+func TestVModule(t *testing.T) {
+	logger, logs := newObservedLogger(InfoLevel)
+
+	if err := logger.SetVModule("vmodule_test=3"); err != nil {
+		t.Fatalf("SetVModule failed: %v", err)
+	}
+	if !logger.V(3).Enabled() {
+		t.Error("Expected V(3) to be enabled for this file")
+	}
+	if logger.V(4).Enabled() {
+		t.Error("Expected V(4) to be disabled for this file")
+	}
+
+	if err := logger.SetVModule("other*=3"); err != nil {
+		t.Fatalf("SetVModule failed: %v", err)
+	}
+	logger.V(3).Info("hidden")
+	if logs.Len() != 0 {
+		t.Errorf("Expected no entries, got %d", logs.Len())
+	}
+}
+
+func TestVModuleConfig(t *testing.T) {
+	logger, err := NewLoggerWithConfig(Config{
+		Level:       InfoLevel,
+		Encoding:    "json",
+		OutputPaths: []string{"stdout"},
+		VModule:     "*/vmodule_test=2",
+	})
+	if err != nil {
+		t.Fatalf("NewLoggerWithConfig failed: %v", err)
+	}
+	defer logger.Sync()
+
+	if !logger.V(2).Enabled() {
+		t.Error("Expected V(2) to be enabled for this file")
+	}
+
+	_, err = NewLoggerWithConfig(Config{
+		Level:       InfoLevel,
+		Encoding:    "json",
+		OutputPaths: []string{"stdout"},
+		VModule:     "cron=high",
+	})
+	if err == nil {
+		t.Error("Expected error for invalid VModule")
+	}
+}
+
+func TestMatchFile(t *testing.T) {
+	tests := []struct {
+		pattern string
+		file    string
+		want    bool
+	}{
+		{"cron*", "/src/app/service/cron_server", true},
+		{"service/cron*", "/src/app/service/cron_server", true},
+		{"service/cron*", "/src/app/other/cron_server", false},
+		{"app/*/cron_server", "/src/app/service/cron_server", true},
+		{"server", "/src/app/service/cron_server", false},
+	}
+
+	for _, tt := range tests {
+		if got := matchFile(tt.pattern, tt.file); got != tt.want {
+			t.Errorf("matchFile(%q, %q) = %v, want %v", tt.pattern, tt.file, got, tt.want)
+		}
+	}
+}